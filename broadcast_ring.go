@@ -0,0 +1,362 @@
+package rubberring
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"sync"
+)
+
+var (
+	ErrSubscriberDropped = errors.New("rubberring: subscriber was dropped for lagging behind")
+	ErrSubscriberClosed  = errors.New("rubberring: subscriber is closed")
+)
+
+type SlowSubscriberPolicy int
+
+const (
+	// BlockProducer makes Push wait until the slowest subscriber catches up
+	BlockProducer SlowSubscriberPolicy = iota
+	// DropSubscriber unsubscribes the lagging subscriber, its Pull returns ErrSubscriberDropped
+	DropSubscriber
+	// SkipAhead moves the lagging subscriber forward, the skipped elements are lost for it
+	SkipAhead
+)
+
+type broadcastConfig struct {
+	maxLag       int64
+	policy       SlowSubscriberPolicy
+	chainOptions []applyConfigFunc
+}
+
+type applyBroadcastConfigFunc func(c *broadcastConfig)
+
+// WithMaxLag limits how many elements a subscriber may lag behind the producer,
+// the policy decides what happens when the limit is reached. 0 (default) means no limit.
+func WithMaxLag(maxLag int, policy SlowSubscriberPolicy) applyBroadcastConfigFunc {
+	if maxLag < 0 {
+		maxLag = 0
+	}
+	return func(c *broadcastConfig) {
+		c.maxLag = int64(maxLag)
+		c.policy = policy
+	}
+}
+
+// WithChainOptions passes options of RubberRing for the chunk chain shared by subscribers
+func WithChainOptions(options ...applyConfigFunc) applyBroadcastConfigFunc {
+	return func(c *broadcastConfig) {
+		c.chainOptions = append(c.chainOptions, options...)
+	}
+}
+
+// BroadcastRing delivers every element to every subscriber. Subscribers have their own cursors
+// into one chunk chain, and a chunk is recycled once every cursor has passed it.
+type BroadcastRing[V any] struct {
+	mu         *sync.Mutex
+	config     config
+	maxLag     int64
+	policy     SlowSubscriberPolicy
+	freeChanks chan *chank[V]
+
+	startChank  *chank[V]
+	startBase   int64
+	endChank    *chank[V]
+	endBase     int64
+	endPosition int
+	tail        int64
+	capacity    int
+	// minPos is the position of the slowest subscriber known at the last recycle,
+	// subscribers only move forward, so it never exceeds the real one
+	minPos      int64
+	subscribers map[*Subscriber[V]]struct{}
+
+	// pushed and pulled are closed and replaced to wake waiting subscribers and producers
+	pushed        chan struct{}
+	pushedWaiters int
+	pulled        chan struct{}
+	pulledWaiters int
+}
+
+type Subscriber[V any] struct {
+	ring    *BroadcastRing[V]
+	chk     *chank[V]
+	base    int64
+	pos     int64
+	skipped int64
+	err     error
+}
+
+func NewBroadcastRing[V any](options ...applyBroadcastConfigFunc) *BroadcastRing[V] {
+	bc := broadcastConfig{}
+	for _, option := range options {
+		option(&bc)
+	}
+	ringConfig := defaultConfig
+	for _, option := range bc.chainOptions {
+		option(&ringConfig)
+	}
+	chanks := createNewChankChain[V](ringConfig.startChankSize, ringConfig.startChankCount)
+	return &BroadcastRing[V]{
+		mu:          &sync.Mutex{},
+		config:      ringConfig,
+		maxLag:      bc.maxLag,
+		policy:      bc.policy,
+		freeChanks:  make(chan *chank[V], ringConfig.pasiveChankBufferSize),
+		startChank:  chanks,
+		endChank:    chanks,
+		capacity:    ringConfig.startChankSize * max(1, ringConfig.startChankCount),
+		subscribers: make(map[*Subscriber[V]]struct{}),
+		pushed:      make(chan struct{}),
+		pulled:      make(chan struct{}),
+	}
+}
+
+// Size returns the number of elements not yet seen by the slowest subscriber
+func (r *BroadcastRing[V]) Size() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return int(r.tail - r.slowestLocked())
+}
+
+func (r *BroadcastRing[V]) Capacity() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.capacity
+}
+
+func (r *BroadcastRing[V]) Subscribers() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.subscribers)
+}
+
+// Subscribe returns a subscriber that sees the elements pushed after this call
+func (r *BroadcastRing[V]) Subscribe() *Subscriber[V] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := &Subscriber[V]{
+		ring: r,
+		chk:  r.endChank,
+		base: r.endBase,
+		pos:  r.tail,
+	}
+	if len(r.subscribers) == 0 {
+		r.minPos = r.tail
+	}
+	r.subscribers[s] = struct{}{}
+	return s
+}
+
+// Push appends the element for all subscribers, it returns an error only if BlockProducer
+// policy is used and ctx is done while waiting for a slow subscriber
+func (r *BroadcastRing[V]) Push(ctx context.Context, value V) error {
+	r.mu.Lock()
+	for r.maxLag > 0 && len(r.subscribers) > 0 && r.tail-r.minPos >= r.maxLag {
+		r.minPos = r.slowestLocked()
+		if r.tail-r.minPos < r.maxLag {
+			break
+		}
+		if r.policy != BlockProducer {
+			r.handleLaggingLocked()
+			break
+		}
+		r.pulledWaiters++
+		pulled := r.pulled
+		r.mu.Unlock()
+		select {
+		case <-pulled:
+		case <-ctx.Done():
+			r.mu.Lock()
+			r.pulledWaiters--
+			r.mu.Unlock()
+			return ctx.Err()
+		}
+		r.mu.Lock()
+		r.pulledWaiters--
+	}
+
+	r.endChank.data[r.endPosition] = value
+	r.endPosition++
+	r.tail++
+	if r.endPosition >= len(r.endChank.data) {
+		r.nextEndChankLocked()
+	}
+	if len(r.subscribers) == 0 {
+		r.recycleLocked()
+	}
+	r.wakeSubscribersLocked()
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *BroadcastRing[V]) wakeSubscribersLocked() {
+	if r.pushedWaiters > 0 {
+		close(r.pushed)
+		r.pushed = make(chan struct{})
+	}
+}
+
+func (r *BroadcastRing[V]) nextEndChankLocked() {
+	next := r.endChank.nextChank
+	if next == nil {
+		select {
+		case next = <-r.freeChanks:
+		default:
+			newChankSize, newChankCount := r.config.growStrategy(r.capacity)
+			next = createNewChankChain[V](newChankSize, newChankCount)
+			r.capacity += newChankSize * max(1, newChankCount)
+		}
+		r.endChank.nextChank = next
+	}
+	r.endBase += int64(len(r.endChank.data))
+	r.endChank = next
+	r.endPosition = 0
+}
+
+func (r *BroadcastRing[V]) slowestLocked() int64 {
+	slowest := r.tail
+	for s := range r.subscribers {
+		slowest = min(slowest, s.pos)
+	}
+	return slowest
+}
+
+// handleLaggingLocked makes room for one more element by dropping or moving the lagging subscribers
+func (r *BroadcastRing[V]) handleLaggingLocked() {
+	limit := r.tail + 1 - r.maxLag
+	for s := range r.subscribers {
+		if s.pos >= limit {
+			continue
+		}
+		if r.policy == DropSubscriber {
+			s.err = ErrSubscriberDropped
+			delete(r.subscribers, s)
+			continue
+		}
+		s.skipped += limit - s.pos
+		s.pos = limit
+		for s.pos-s.base >= int64(len(s.chk.data)) {
+			s.base += int64(len(s.chk.data))
+			s.chk = s.chk.nextChank
+		}
+	}
+	r.recycleLocked()
+}
+
+// recycleLocked puts the chunks passed by all subscribers to freeChanks
+func (r *BroadcastRing[V]) recycleLocked() {
+	r.minPos = r.slowestLocked()
+	for r.startChank != r.endChank && r.startBase+int64(len(r.startChank.data)) <= r.minPos {
+		chk := r.startChank
+		r.startChank = chk.nextChank
+		r.startBase += int64(len(chk.data))
+		chk.nextChank = nil
+		clear(chk.data)
+		select {
+		case r.freeChanks <- chk:
+		default:
+			r.capacity -= len(chk.data)
+		}
+	}
+	if r.pulledWaiters > 0 {
+		close(r.pulled)
+		r.pulled = make(chan struct{})
+	}
+}
+
+// Skipped returns the number of elements the subscriber lost because of SkipAhead policy
+func (s *Subscriber[V]) Skipped() int64 {
+	s.ring.mu.Lock()
+	defer s.ring.mu.Unlock()
+	return s.skipped
+}
+
+// Lag returns the number of elements the subscriber has not seen yet
+func (s *Subscriber[V]) Lag() int {
+	s.ring.mu.Lock()
+	defer s.ring.mu.Unlock()
+	return int(s.ring.tail - s.pos)
+}
+
+// TryPull returns io.EOF if the subscriber has seen all elements
+func (s *Subscriber[V]) TryPull() (V, error) {
+	s.ring.mu.Lock()
+	defer s.ring.mu.Unlock()
+	return s.pullLocked()
+}
+
+func (s *Subscriber[V]) Pull(ctx context.Context) (V, error) {
+	r := s.ring
+	r.mu.Lock()
+	for {
+		v, err := s.pullLocked()
+		if err != io.EOF {
+			r.mu.Unlock()
+			return v, err
+		}
+		r.pushedWaiters++
+		pushed := r.pushed
+		r.mu.Unlock()
+		select {
+		case <-pushed:
+		case <-ctx.Done():
+			r.mu.Lock()
+			r.pushedWaiters--
+			r.mu.Unlock()
+			return v, ctx.Err()
+		}
+		r.mu.Lock()
+		r.pushedWaiters--
+	}
+}
+
+func (s *Subscriber[V]) pullLocked() (V, error) {
+	var v V
+	if s.err != nil {
+		return v, s.err
+	}
+	if s.pos == s.ring.tail {
+		return v, io.EOF
+	}
+	v = s.chk.data[s.pos-s.base]
+	s.pos++
+	if s.pos-s.base >= int64(len(s.chk.data)) {
+		s.base += int64(len(s.chk.data))
+		s.chk = s.chk.nextChank
+		s.ring.recycleLocked()
+	} else if s.ring.pulledWaiters > 0 {
+		s.ring.recycleLocked()
+	}
+	return v, nil
+}
+
+// Close unsubscribes, so the ring does not keep elements for the subscriber anymore
+func (s *Subscriber[V]) Close() {
+	r := s.ring
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s.err != nil {
+		return
+	}
+	s.err = ErrSubscriberClosed
+	delete(r.subscribers, s)
+	r.recycleLocked()
+	// a Pull of this subscriber may wait for the next element
+	r.wakeSubscribersLocked()
+}
+
+func (s *Subscriber[V]) Elements(ctx context.Context) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for {
+			v, err := s.Pull(ctx)
+			if err != nil {
+				return
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
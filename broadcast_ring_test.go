@@ -0,0 +1,201 @@
+package rubberring
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/goleak"
+)
+
+type BroadcastRingSuite struct {
+	suite.Suite
+	ring *BroadcastRing[int]
+}
+
+func (s *BroadcastRingSuite) SetupTest() {
+	s.ring = NewBroadcastRing[int](
+		WithChainOptions(
+			WithStartChankSize(4),
+			WithStartChankCount(1),
+			WithGrowStrategy(func(int) (int, int) { return 4, 1 }),
+		),
+	)
+}
+
+func (s *BroadcastRingSuite) TearDownTest() {
+	s.NoError(goleak.Find())
+	s.ring = nil
+}
+
+func (s *BroadcastRingSuite) push(values ...int) {
+	for _, v := range values {
+		s.Require().NoError(s.ring.Push(context.Background(), v))
+	}
+}
+
+func (s *BroadcastRingSuite) drain(sub *Subscriber[int]) []int {
+	result := []int{}
+	for {
+		v, err := sub.TryPull()
+		if err != nil {
+			s.Equal(io.EOF, err)
+			return result
+		}
+		result = append(result, v)
+	}
+}
+
+func (s *BroadcastRingSuite) TestEverySubscriberSeesEveryElement() {
+	s.push(0)
+	first := s.ring.Subscribe()
+	s.push(1, 2, 3)
+	second := s.ring.Subscribe()
+	s.push(4, 5)
+
+	s.Equal([]int{1, 2, 3, 4, 5}, s.drain(first))
+	s.Equal([]int{4, 5}, s.drain(second))
+	s.Equal(2, s.ring.Subscribers())
+	s.Equal(0, s.ring.Size())
+}
+
+func (s *BroadcastRingSuite) TestChanksAreRecycledAfterSlowestCursor() {
+	fast := s.ring.Subscribe()
+	slow := s.ring.Subscribe()
+	for i := range 12 {
+		s.push(i)
+	}
+	s.Equal(16, s.ring.Capacity())
+
+	s.Len(s.drain(fast), 12)
+	s.Equal(0, len(s.ring.freeChanks))
+	s.Equal(12, s.ring.Size())
+
+	s.Len(s.drain(slow), 12)
+	s.Equal(3, len(s.ring.freeChanks))
+
+	// chunks from freeChanks are reused, so the capacity does not grow
+	for i := range 12 {
+		s.push(i)
+	}
+	s.Equal(16, s.ring.Capacity())
+	s.Len(s.drain(slow), 12)
+	s.Len(s.drain(fast), 12)
+}
+
+func (s *BroadcastRingSuite) TestWithoutSubscribers() {
+	for i := range 100 {
+		s.push(i)
+	}
+	s.Equal(0, s.ring.Size())
+	s.LessOrEqual(s.ring.Capacity(), 16)
+}
+
+func (s *BroadcastRingSuite) TestSkipAhead() {
+	s.ring = NewBroadcastRing[int](WithMaxLag(3, SkipAhead))
+	sub := s.ring.Subscribe()
+	s.push(1, 2, 3, 4, 5)
+	s.Equal(3, sub.Lag())
+	s.Equal(int64(2), sub.Skipped())
+	s.Equal([]int{3, 4, 5}, s.drain(sub))
+}
+
+func (s *BroadcastRingSuite) TestDropSubscriber() {
+	s.ring = NewBroadcastRing[int](WithMaxLag(2, DropSubscriber))
+	slow := s.ring.Subscribe()
+	fast := s.ring.Subscribe()
+	for i := range 5 {
+		s.push(i)
+		_, err := fast.TryPull()
+		s.NoError(err)
+	}
+	_, err := slow.TryPull()
+	s.ErrorIs(err, ErrSubscriberDropped)
+	s.Equal(1, s.ring.Subscribers())
+
+	fast.Close()
+	_, err = fast.TryPull()
+	s.ErrorIs(err, ErrSubscriberClosed)
+}
+
+func (s *BroadcastRingSuite) TestBlockProducer() {
+	s.ring = NewBroadcastRing[int](WithMaxLag(2, BlockProducer))
+	sub := s.ring.Subscribe()
+	s.push(1, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	s.ErrorIs(s.ring.Push(ctx, 3), context.DeadlineExceeded)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.NoError(s.ring.Push(context.Background(), 3))
+	}()
+	time.Sleep(10 * time.Millisecond)
+	v, err := sub.TryPull()
+	s.NoError(err)
+	s.Equal(1, v)
+	<-done
+	s.Equal([]int{2, 3}, s.drain(sub))
+}
+
+func (s *BroadcastRingSuite) TestConcurrent() {
+	const count = 5000
+	s.ring = NewBroadcastRing[int](
+		WithMaxLag(64, BlockProducer),
+		WithChainOptions(WithStartChankSize(16), WithStartChankCount(1)),
+	)
+
+	wg := &sync.WaitGroup{}
+	for range 4 {
+		sub := s.ring.Subscribe()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer sub.Close()
+			expected := 0
+			for v := range sub.Elements(context.Background()) {
+				s.Equal(expected, v)
+				expected++
+				if expected == count {
+					return
+				}
+			}
+		}()
+	}
+	for i := range count {
+		s.NoError(s.ring.Push(context.Background(), i))
+	}
+	wg.Wait()
+	s.Equal(0, s.ring.Subscribers())
+}
+
+func (s *BroadcastRingSuite) TestCloseWakesPull() {
+	sub := s.ring.Subscribe()
+	done := make(chan error)
+	go func() {
+		_, err := sub.Pull(context.Background())
+		done <- err
+	}()
+	s.Eventually(func() bool {
+		s.ring.mu.Lock()
+		defer s.ring.mu.Unlock()
+		return s.ring.pushedWaiters == 1
+	}, time.Second, time.Millisecond)
+
+	sub.Close()
+	select {
+	case err := <-done:
+		s.ErrorIs(err, ErrSubscriberClosed)
+	case <-time.After(time.Second):
+		s.Fail("Pull is not woken by Close")
+	}
+}
+
+func TestBroadcastRingSuite(t *testing.T) {
+	suite.Run(t, new(BroadcastRingSuite))
+}
@@ -0,0 +1,313 @@
+package rubberring
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type cacheConfig struct {
+	smallRatio   float64
+	queueOptions []applyConfigFunc
+}
+
+type applyCacheConfigFunc func(c *cacheConfig)
+
+// WithSmallRatio sets the share of the capacity taken by the queue of new keys (0.1 by default)
+func WithSmallRatio(ratio float64) applyCacheConfigFunc {
+	return func(c *cacheConfig) {
+		if ratio > 0 && ratio < 1 {
+			c.smallRatio = ratio
+		}
+	}
+}
+
+// WithCacheQueueOptions passes options to the RubberRings of the small, main and ghost queues,
+// WithElementTTL is ignored
+func WithCacheQueueOptions(options ...applyConfigFunc) applyCacheConfigFunc {
+	return func(c *cacheConfig) {
+		c.queueOptions = append(c.queueOptions, options...)
+	}
+}
+
+const maxCacheFreq = 3
+
+type cacheEntry[K comparable, V any] struct {
+	key     K
+	value   V
+	freq    atomic.Int32
+	main    bool
+	deleted bool
+}
+
+type ghostKey[K comparable] struct {
+	key K
+	seq uint64
+}
+
+type CacheStat struct {
+	Size      int
+	Capacity  int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Small     RubberRingStat
+	Main      RubberRingStat
+	Ghost     RubberRingStat
+}
+
+// HitRatio returns the share of Get calls that found the key
+func (s CacheStat) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// Cache is a bounded cache with S3-FIFO eviction. New keys go to the small queue,
+// keys accessed there more than once move to the main queue, the rest are evicted
+// and remembered in the ghost queue, so a key that comes back soon goes straight to the main queue.
+// The main queue is a CLOCK: accessed keys are pushed back instead of being evicted.
+// All queues are RubberRings, so the memory follows the number of keys.
+// Cache is not safe for concurrent use, see SyncCache.
+type Cache[K comparable, V any] struct {
+	capacity      int
+	smallCapacity int
+	ghostCapacity int
+
+	index     map[K]*cacheEntry[K, V]
+	small     *RubberRing[*cacheEntry[K, V]]
+	main      *RubberRing[*cacheEntry[K, V]]
+	smallSize int
+	mainSize  int
+	// stale counts the deleted entries left in the queues
+	stale int
+
+	ghost    *RubberRing[ghostKey[K]]
+	ghosts   map[K]uint64
+	ghostSeq uint64
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions uint64
+}
+
+func NewCache[K comparable, V any](capacity int, options ...applyCacheConfigFunc) *Cache[K, V] {
+	config := cacheConfig{smallRatio: 0.1}
+	for _, option := range options {
+		option(&config)
+	}
+	config.queueOptions = append(config.queueOptions, withoutTTL())
+	capacity = max(1, capacity)
+	smallCapacity := max(1, int(float64(capacity)*config.smallRatio))
+	return &Cache[K, V]{
+		capacity:      capacity,
+		smallCapacity: smallCapacity,
+		ghostCapacity: max(1, capacity-smallCapacity),
+		index:         make(map[K]*cacheEntry[K, V]),
+		small:         NewRubberRing[*cacheEntry[K, V]](config.queueOptions...),
+		main:          NewRubberRing[*cacheEntry[K, V]](config.queueOptions...),
+		ghost:         NewRubberRing[ghostKey[K]](config.queueOptions...),
+		ghosts:        make(map[K]uint64),
+	}
+}
+
+// Size returns the number of cached keys
+func (c *Cache[K, V]) Size() int {
+	return len(c.index)
+}
+
+func (c *Cache[K, V]) Stat() CacheStat {
+	return CacheStat{
+		Size:      len(c.index),
+		Capacity:  c.capacity,
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions,
+		Small:     c.small.Stat(),
+		Main:      c.main.Stat(),
+		Ghost:     c.ghost.Stat(),
+	}
+}
+
+// Get returns the value of the key and marks the key as accessed,
+// it changes only atomics, so SyncCache calls it under a read lock
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	entry, ok := c.index[key]
+	if !ok {
+		c.misses.Add(1)
+		var v V
+		return v, false
+	}
+	c.hits.Add(1)
+	if freq := entry.freq.Load(); freq < maxCacheFreq {
+		entry.freq.CompareAndSwap(freq, freq+1)
+	}
+	return entry.value, true
+}
+
+// Set puts the value of the key, evicting another key if the cache is full
+func (c *Cache[K, V]) Set(key K, value V) {
+	if entry, ok := c.index[key]; ok {
+		entry.value = value
+		return
+	}
+	for len(c.index) >= c.capacity {
+		if c.smallSize >= c.smallCapacity || c.mainSize == 0 {
+			c.evictSmall()
+		} else {
+			c.evictMain()
+		}
+	}
+	entry := &cacheEntry[K, V]{key: key, value: value}
+	if _, ok := c.ghosts[key]; ok {
+		delete(c.ghosts, key)
+		entry.main = true
+		c.main.Push(entry)
+		c.mainSize++
+	} else {
+		c.small.Push(entry)
+		c.smallSize++
+	}
+	c.index[key] = entry
+}
+
+// Delete removes the key, it returns false if the key is not cached
+func (c *Cache[K, V]) Delete(key K) bool {
+	entry, ok := c.index[key]
+	if !ok {
+		return false
+	}
+	delete(c.index, key)
+	entry.deleted = true
+	if entry.main {
+		c.mainSize--
+	} else {
+		c.smallSize--
+	}
+	c.stale++
+	if c.stale > len(c.index)+64 {
+		c.compact()
+	}
+	return true
+}
+
+// evictSmall takes the first entry of the small queue and either moves it
+// to the main queue or evicts it to the ghost queue
+func (c *Cache[K, V]) evictSmall() {
+	for {
+		entry, err := c.small.Pull()
+		if err != nil {
+			return
+		}
+		if entry.deleted {
+			c.stale--
+			continue
+		}
+		c.smallSize--
+		if entry.freq.Load() > 1 {
+			entry.main = true
+			entry.freq.Store(0)
+			c.main.Push(entry)
+			c.mainSize++
+			return
+		}
+		delete(c.index, entry.key)
+		c.evictions++
+		c.pushGhost(entry.key)
+		return
+	}
+}
+
+// evictMain evicts the first entry of the main queue that was not accessed since its last pass
+func (c *Cache[K, V]) evictMain() {
+	for {
+		entry, err := c.main.Pull()
+		if err != nil {
+			return
+		}
+		if entry.deleted {
+			c.stale--
+			continue
+		}
+		if freq := entry.freq.Load(); freq > 0 {
+			entry.freq.Store(freq - 1)
+			c.main.Push(entry)
+			continue
+		}
+		c.mainSize--
+		delete(c.index, entry.key)
+		c.evictions++
+		return
+	}
+}
+
+// pushGhost remembers the evicted key, the ghost queue keeps sequence numbers,
+// so a key removed from the ghosts and evicted again is not forgotten by its old record
+func (c *Cache[K, V]) pushGhost(key K) {
+	for c.ghost.Size() >= c.ghostCapacity {
+		old, _ := c.ghost.Pull()
+		if seq, ok := c.ghosts[old.key]; ok && seq == old.seq {
+			delete(c.ghosts, old.key)
+		}
+	}
+	c.ghostSeq++
+	c.ghosts[key] = c.ghostSeq
+	c.ghost.Push(ghostKey[K]{key: key, seq: c.ghostSeq})
+}
+
+// compact drops the deleted entries from the queues
+func (c *Cache[K, V]) compact() {
+	for _, queue := range []*RubberRing[*cacheEntry[K, V]]{c.small, c.main} {
+		for range queue.Size() {
+			entry, _ := queue.Pull()
+			if !entry.deleted {
+				queue.Push(entry)
+			}
+		}
+	}
+	c.stale = 0
+}
+
+// SyncCache is a Cache safe for concurrent use. Get takes only a read lock,
+// the access marks and the hit counters are atomics.
+type SyncCache[K comparable, V any] struct {
+	mu    *sync.RWMutex
+	cache *Cache[K, V]
+}
+
+func NewSyncCache[K comparable, V any](capacity int, options ...applyCacheConfigFunc) *SyncCache[K, V] {
+	return &SyncCache[K, V]{
+		mu:    &sync.RWMutex{},
+		cache: NewCache[K, V](capacity, options...),
+	}
+}
+
+func (c *SyncCache[K, V]) Size() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cache.Size()
+}
+
+func (c *SyncCache[K, V]) Stat() CacheStat {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cache.Stat()
+}
+
+func (c *SyncCache[K, V]) Get(key K) (V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cache.Get(key)
+}
+
+func (c *SyncCache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache.Set(key, value)
+}
+
+func (c *SyncCache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Delete(key)
+}
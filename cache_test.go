@@ -0,0 +1,193 @@
+package rubberring
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/goleak"
+)
+
+type CacheSuite struct {
+	suite.Suite
+	cache *Cache[int, int]
+}
+
+func (s *CacheSuite) SetupTest() {
+	s.cache = NewCache[int, int](10)
+}
+
+func (s *CacheSuite) TearDownTest() {
+	s.NoError(goleak.Find())
+	s.cache = nil
+}
+
+func (s *CacheSuite) TestGetSetDelete() {
+	s.cache.Set(1, 10)
+	s.cache.Set(2, 20)
+	s.cache.Set(1, 11)
+	s.Equal(2, s.cache.Size())
+
+	v, ok := s.cache.Get(1)
+	s.True(ok)
+	s.Equal(11, v)
+	_, ok = s.cache.Get(3)
+	s.False(ok)
+
+	s.True(s.cache.Delete(1))
+	s.False(s.cache.Delete(1))
+	_, ok = s.cache.Get(1)
+	s.False(ok)
+	s.Equal(1, s.cache.Size())
+
+	stat := s.cache.Stat()
+	s.Equal(uint64(1), stat.Hits)
+	s.Equal(uint64(2), stat.Misses)
+	s.InDelta(1.0/3, stat.HitRatio(), 1e-9)
+}
+
+func (s *CacheSuite) TestScanResistance() {
+	for i := 1; i <= 10; i++ {
+		s.cache.Set(i, i)
+	}
+	for range 2 {
+		for i := 1; i <= 5; i++ {
+			s.cache.Get(i)
+		}
+	}
+	// keys seen once are evicted from the small queue and do not touch the accessed ones
+	for i := 11; i <= 1000; i++ {
+		s.cache.Set(i, i)
+	}
+	for i := 1; i <= 5; i++ {
+		_, ok := s.cache.Get(i)
+		s.True(ok, "key %d", i)
+	}
+	s.Equal(10, s.cache.Size())
+	s.Equal(uint64(990), s.cache.Stat().Evictions)
+}
+
+func (s *CacheSuite) TestGhost() {
+	for i := 1; i <= 11; i++ {
+		s.cache.Set(i, i)
+	}
+	_, ok := s.cache.Get(1)
+	s.False(ok)
+	s.Contains(s.cache.ghosts, 1)
+
+	// an evicted key that comes back soon goes straight to the main queue
+	s.cache.Set(1, 1)
+	s.True(s.cache.index[1].main)
+	s.NotContains(s.cache.ghosts, 1)
+	s.Equal(10, s.cache.Size())
+}
+
+func (s *CacheSuite) TestMainSecondChance() {
+	for i := 1; i <= 10; i++ {
+		s.cache.Set(i, i)
+	}
+	for i := 1; i <= 9; i++ {
+		s.cache.Get(i)
+		s.cache.Get(i)
+	}
+	s.cache.Set(11, 11) // moves 1..9 to the main queue, evicts 10
+	s.Equal(9, s.cache.mainSize)
+
+	s.cache.Get(1)
+	s.cache.Set(12, 12) // evicts 11 from the small queue
+	s.cache.Set(13, 13) // the small queue is full again, so the main queue is not touched
+	_, ok := s.cache.Get(12)
+	s.False(ok)
+
+	// main keys lost their marks on the move except 1, which is accessed again
+	s.cache.smallCapacity = 2
+	s.cache.Set(14, 14)
+	_, ok = s.cache.Get(2)
+	s.False(ok)
+	_, ok = s.cache.Get(1)
+	s.True(ok)
+}
+
+func (s *CacheSuite) TestDeleteCompacts() {
+	for i := range 1000 {
+		s.cache.Set(i, i)
+		s.cache.Delete(i)
+	}
+	s.Equal(0, s.cache.Size())
+	s.Less(s.cache.small.Size(), 100)
+
+	for i := range 10 {
+		s.cache.Set(i, i)
+	}
+	s.Equal(10, s.cache.Size())
+	s.Equal(0, s.cache.smallSize+s.cache.mainSize-10)
+}
+
+func (s *CacheSuite) TestQueuesShrink() {
+	s.cache = NewCache[int, int](100, WithSmallRatio(0.5),
+		WithCacheQueueOptions(
+			WithStartChankSize(8),
+			WithStartChankCount(1),
+			WithPassiveChankBufferSize(1),
+			WithGrowStrategy(func(int) (int, int) { return 8, 1 }),
+		))
+	for i := range 100 {
+		s.cache.Set(i, i)
+	}
+	s.Greater(s.cache.Stat().Small.Capacity, 100)
+
+	for i := range 100 {
+		s.cache.Delete(i)
+	}
+	for i := 100; i < 110; i++ {
+		s.cache.Set(i, i)
+	}
+	stat := s.cache.Stat()
+	s.Less(stat.Small.Capacity, 40)
+	s.Equal(10, stat.Size)
+	s.Equal(100, stat.Capacity)
+}
+
+func (s *CacheSuite) TestSyncCache() {
+	cache := NewSyncCache[int, int](100)
+	wg := &sync.WaitGroup{}
+	for w := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				key := (i * (w + 1)) % 300
+				if _, ok := cache.Get(key); !ok {
+					cache.Set(key, i)
+				}
+				if i%10 == 0 {
+					cache.Delete(key)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	s.LessOrEqual(cache.Size(), 100)
+	stat := cache.Stat()
+	s.Equal(uint64(4000), stat.Hits+stat.Misses)
+}
+
+func (s *CacheSuite) TestElementTTLIgnored() {
+	clock := newFakeClock()
+	s.cache = NewCache[int, int](2, WithCacheQueueOptions(WithElementTTL(time.Second), WithClock(clock)))
+	s.cache.Set(1, 1)
+	s.cache.Get(1)
+	s.cache.Get(1)
+	clock.Advance(time.Hour)
+	s.cache.Set(2, 2)
+	s.cache.Set(3, 3)
+
+	_, ok := s.cache.Get(1)
+	s.True(ok)
+	s.Equal(2, s.cache.Size())
+}
+
+func TestCacheSuite(t *testing.T) {
+	suite.Run(t, new(CacheSuite))
+}
@@ -0,0 +1,186 @@
+package rubberring
+
+import (
+	"context"
+	"io"
+	"iter"
+	"sync"
+
+	syncutils "github.com/Skrip42/syncUtils"
+)
+
+type coalescingConfig struct {
+	moveToTail   bool
+	queueOptions []applyConfigFunc
+}
+
+type applyCoalescingConfigFunc func(c *coalescingConfig)
+
+// WithMoveToTail moves a replaced element to the end of the queue,
+// by default it keeps the position of the first pending value
+func WithMoveToTail() applyCoalescingConfigFunc {
+	return func(c *coalescingConfig) {
+		c.moveToTail = true
+	}
+}
+
+// WithQueueOptions passes options to the RubberRing that keeps the order of the keys,
+// WithElementTTL is ignored
+func WithQueueOptions(options ...applyConfigFunc) applyCoalescingConfigFunc {
+	return func(c *coalescingConfig) {
+		c.queueOptions = append(c.queueOptions, options...)
+	}
+}
+
+type coalescedKey[K comparable] struct {
+	key K
+	gen uint64
+}
+
+type coalescedValue[V any] struct {
+	value V
+	gen   uint64
+}
+
+// CoalescingRing keeps only the latest pending value per key. The queue holds keys with generations,
+// a key moved to the tail leaves a stale generation behind, which Pull skips.
+type CoalescingRing[K comparable, V any] struct {
+	mu         *sync.Mutex
+	cond       *syncutils.Cond
+	moveToTail bool
+	queue      *RubberRing[coalescedKey[K]]
+	pending    map[K]coalescedValue[V]
+	gen        uint64
+	stale      int
+	coalesced  uint64
+}
+
+func NewCoalescingRing[K comparable, V any](options ...applyCoalescingConfigFunc) *CoalescingRing[K, V] {
+	config := coalescingConfig{}
+	for _, option := range options {
+		option(&config)
+	}
+	config.queueOptions = append(config.queueOptions, withoutTTL())
+	return &CoalescingRing[K, V]{
+		mu:         &sync.Mutex{},
+		cond:       syncutils.NewCond(),
+		moveToTail: config.moveToTail,
+		queue:      NewRubberRing[coalescedKey[K]](config.queueOptions...),
+		pending:    make(map[K]coalescedValue[V]),
+	}
+}
+
+// Size returns the number of keys with a pending value
+func (r *CoalescingRing[K, V]) Size() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pending)
+}
+
+// Coalesced returns the number of values replaced before they were pulled
+func (r *CoalescingRing[K, V]) Coalesced() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.coalesced
+}
+
+func (r *CoalescingRing[K, V]) Push(key K, value V) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.pending[key]
+	if ok {
+		r.coalesced++
+		if !r.moveToTail {
+			entry.value = value
+			r.pending[key] = entry
+			return
+		}
+		r.stale++
+	}
+	r.gen++
+	r.pending[key] = coalescedValue[V]{value: value, gen: r.gen}
+	r.queue.Push(coalescedKey[K]{key: key, gen: r.gen})
+	if r.stale > len(r.pending)+64 {
+		r.compactLocked()
+	}
+	if !ok {
+		r.cond.Signal()
+	}
+}
+
+// TryPull returns io.EOF instead of waiting if the ring is empty
+func (r *CoalescingRing[K, V]) TryPull() (K, V, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pullLocked()
+}
+
+// Pull waits for a pending value and returns it with its key
+func (r *CoalescingRing[K, V]) Pull(ctx context.Context) (K, V, error) {
+	r.mu.Lock()
+	for {
+		key, v, err := r.pullLocked()
+		if err == nil {
+			r.mu.Unlock()
+			return key, v, nil
+		}
+		wait := r.cond.Wait()
+		r.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			select {
+			case <-wait:
+				// the signal was meant for an element, pass it to another waiter
+				r.mu.Lock()
+				r.cond.Signal()
+				r.mu.Unlock()
+			default:
+			}
+			return key, v, ctx.Err()
+		}
+		r.mu.Lock()
+	}
+}
+
+func (r *CoalescingRing[K, V]) Elements(ctx context.Context) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for {
+			key, v, err := r.Pull(ctx)
+			if err != nil {
+				return
+			}
+			if !yield(key, v) {
+				return
+			}
+		}
+	}
+}
+
+func (r *CoalescingRing[K, V]) pullLocked() (K, V, error) {
+	for {
+		item, err := r.queue.Pull()
+		if err != nil {
+			var v V
+			return item.key, v, io.EOF
+		}
+		entry, ok := r.pending[item.key]
+		if !ok || entry.gen != item.gen {
+			r.stale--
+			continue
+		}
+		delete(r.pending, item.key)
+		return item.key, entry.value, nil
+	}
+}
+
+// compactLocked drops the stale keys from the queue
+func (r *CoalescingRing[K, V]) compactLocked() {
+	for range r.queue.Size() {
+		item, _ := r.queue.Pull()
+		if entry, ok := r.pending[item.key]; ok && entry.gen == item.gen {
+			r.queue.Push(item)
+		}
+	}
+	r.stale = 0
+}
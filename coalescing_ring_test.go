@@ -0,0 +1,148 @@
+package rubberring
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/goleak"
+)
+
+type CoalescingRingSuite struct {
+	suite.Suite
+	ring *CoalescingRing[string, int]
+}
+
+func (s *CoalescingRingSuite) SetupTest() {
+	s.ring = NewCoalescingRing[string, int]()
+}
+
+func (s *CoalescingRingSuite) TearDownTest() {
+	s.NoError(goleak.Find())
+	s.ring = nil
+}
+
+func (s *CoalescingRingSuite) drain() ([]string, []int) {
+	keys, values := []string{}, []int{}
+	for {
+		key, v, err := s.ring.TryPull()
+		if err != nil {
+			s.Equal(io.EOF, err)
+			return keys, values
+		}
+		keys = append(keys, key)
+		values = append(values, v)
+	}
+}
+
+func (s *CoalescingRingSuite) TestKeepPosition() {
+	s.ring.Push("a", 1)
+	s.ring.Push("b", 2)
+	s.ring.Push("a", 3)
+	s.ring.Push("c", 4)
+	s.ring.Push("a", 5)
+	s.Equal(3, s.ring.Size())
+	s.Equal(uint64(2), s.ring.Coalesced())
+
+	keys, values := s.drain()
+	s.Equal([]string{"a", "b", "c"}, keys)
+	s.Equal([]int{5, 2, 4}, values)
+}
+
+func (s *CoalescingRingSuite) TestMoveToTail() {
+	s.ring = NewCoalescingRing[string, int](WithMoveToTail())
+	s.ring.Push("a", 1)
+	s.ring.Push("b", 2)
+	s.ring.Push("a", 3)
+	s.ring.Push("c", 4)
+	s.Equal(3, s.ring.Size())
+
+	keys, values := s.drain()
+	s.Equal([]string{"b", "a", "c"}, keys)
+	s.Equal([]int{2, 3, 4}, values)
+	s.Equal(0, s.ring.stale)
+}
+
+func (s *CoalescingRingSuite) TestPushAfterPull() {
+	s.ring.Push("a", 1)
+	key, v, err := s.ring.TryPull()
+	s.NoError(err)
+	s.Equal("a", key)
+	s.Equal(1, v)
+
+	s.ring.Push("a", 2)
+	_, v, err = s.ring.TryPull()
+	s.NoError(err)
+	s.Equal(2, v)
+	s.Equal(uint64(0), s.ring.Coalesced())
+}
+
+func (s *CoalescingRingSuite) TestCompaction() {
+	s.ring = NewCoalescingRing[string, int](WithMoveToTail())
+	for i := range 1000 {
+		s.ring.Push("a", i)
+		s.ring.Push("b", i)
+	}
+	s.Less(s.ring.queue.Size(), 100)
+	keys, values := s.drain()
+	s.Equal([]string{"a", "b"}, keys)
+	s.Equal([]int{999, 999}, values)
+}
+
+func (s *CoalescingRingSuite) TestPullWaits() {
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		s.ring.Push("a", 7)
+	}()
+	key, v, err := s.ring.Pull(context.Background())
+	s.NoError(err)
+	s.Equal("a", key)
+	s.Equal(7, v)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, _, err = s.ring.Pull(ctx)
+	s.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func (s *CoalescingRingSuite) TestLatestValueWins() {
+	const updates = 5000
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range updates {
+			s.ring.Push("a", i)
+		}
+	}()
+
+	last := -1
+	for _, v := range s.ring.Elements(context.Background()) {
+		s.Greater(v, last)
+		last = v
+		if v == updates-1 {
+			break
+		}
+	}
+	wg.Wait()
+	s.Equal(0, s.ring.Size())
+}
+
+func (s *CoalescingRingSuite) TestElementTTLIgnored() {
+	clock := newFakeClock()
+	s.ring = NewCoalescingRing[string, int](WithQueueOptions(WithElementTTL(time.Second), WithClock(clock)))
+	s.ring.Push("a", 1)
+	s.ring.Push("b", 2)
+	clock.Advance(time.Hour)
+
+	keys, values := s.drain()
+	s.Equal([]string{"a", "b"}, keys)
+	s.Equal([]int{1, 2}, values)
+}
+
+func TestCoalescingRingSuite(t *testing.T) {
+	suite.Run(t, new(CoalescingRingSuite))
+}
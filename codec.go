@@ -0,0 +1,102 @@
+package rubberring
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"slices"
+)
+
+// Codec converts ring elements to bytes and back.
+// Encode appends the encoded element to dst and returns the extended slice,
+// Decode must not retain data after returning.
+type Codec[V any] interface {
+	Encode(dst []byte, v V) ([]byte, error)
+	Decode(data []byte) (V, error)
+}
+
+type Numeric interface {
+	~int8 | ~int16 | ~int32 | ~int64 |
+		~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64 | ~complex64 | ~complex128
+}
+
+type numericCodec[V Numeric] struct{}
+
+func NumericCodec[V Numeric]() Codec[V] {
+	return numericCodec[V]{}
+}
+
+func (numericCodec[V]) Encode(dst []byte, v V) ([]byte, error) {
+	return binary.Append(dst, binary.LittleEndian, v)
+}
+
+func (numericCodec[V]) Decode(data []byte) (V, error) {
+	var v V
+	_, err := binary.Decode(data, binary.LittleEndian, &v)
+	return v, err
+}
+
+type bytesCodec struct{}
+
+func BytesCodec() Codec[[]byte] {
+	return bytesCodec{}
+}
+
+func (bytesCodec) Encode(dst []byte, v []byte) ([]byte, error) {
+	return append(dst, v...), nil
+}
+
+func (bytesCodec) Decode(data []byte) ([]byte, error) {
+	return slices.Clone(data), nil
+}
+
+type stringCodec struct{}
+
+func StringCodec() Codec[string] {
+	return stringCodec{}
+}
+
+func (stringCodec) Encode(dst []byte, v string) ([]byte, error) {
+	return append(dst, v...), nil
+}
+
+func (stringCodec) Decode(data []byte) (string, error) {
+	return string(data), nil
+}
+
+type gobCodec[V any] struct{}
+
+func GobCodec[V any]() Codec[V] {
+	return gobCodec[V]{}
+}
+
+func (gobCodec[V]) Encode(dst []byte, v V) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	err := gob.NewEncoder(buf).Encode(&v)
+	return buf.Bytes(), err
+}
+
+func (gobCodec[V]) Decode(data []byte) (V, error) {
+	var v V
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v)
+	return v, err
+}
+
+type jsonCodec[V any] struct{}
+
+func JSONCodec[V any]() Codec[V] {
+	return jsonCodec[V]{}
+}
+
+func (jsonCodec[V]) Encode(dst []byte, v V) ([]byte, error) {
+	data, err := json.Marshal(v)
+	return append(dst, data...), err
+}
+
+func (jsonCodec[V]) Decode(data []byte) (V, error) {
+	var v V
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
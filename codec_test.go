@@ -0,0 +1,68 @@
+package rubberring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CodecSuite struct {
+	suite.Suite
+}
+
+type codecTestStruct struct {
+	Name  string
+	Count int
+}
+
+func roundTrip[V any](s *CodecSuite, codec Codec[V], v V) V {
+	data, err := codec.Encode([]byte{0xff}, v)
+	s.Require().NoError(err)
+	s.Equal(byte(0xff), data[0])
+	got, err := codec.Decode(data[1:])
+	s.Require().NoError(err)
+	return got
+}
+
+func (s *CodecSuite) TestNumericCodec() {
+	s.Equal(int64(-42), roundTrip(s, NumericCodec[int64](), -42))
+	s.Equal(uint8(7), roundTrip(s, NumericCodec[uint8](), 7))
+	s.Equal(3.5, roundTrip(s, NumericCodec[float64](), 3.5))
+	s.Equal(complex64(1+2i), roundTrip(s, NumericCodec[complex64](), 1+2i))
+
+	_, err := NumericCodec[int32]().Decode([]byte{1})
+	s.Error(err)
+}
+
+func (s *CodecSuite) TestBytesCodec() {
+	codec := BytesCodec()
+	data := []byte("payload")
+	s.Equal(data, roundTrip(s, codec, data))
+
+	got, err := codec.Decode(data)
+	s.NoError(err)
+	data[0] = 'P'
+	s.Equal([]byte("payload"), got)
+}
+
+func (s *CodecSuite) TestStringCodec() {
+	s.Equal("payload", roundTrip(s, StringCodec(), "payload"))
+	s.Equal("", roundTrip(s, StringCodec(), ""))
+}
+
+func (s *CodecSuite) TestGobCodec() {
+	v := codecTestStruct{Name: "gob", Count: 3}
+	s.Equal(v, roundTrip(s, GobCodec[codecTestStruct](), v))
+}
+
+func (s *CodecSuite) TestJSONCodec() {
+	v := codecTestStruct{Name: "json", Count: 5}
+	s.Equal(v, roundTrip(s, JSONCodec[codecTestStruct](), v))
+
+	_, err := JSONCodec[codecTestStruct]().Decode([]byte("{"))
+	s.Error(err)
+}
+
+func TestCodecSuite(t *testing.T) {
+	suite.Run(t, new(CodecSuite))
+}
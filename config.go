@@ -1,10 +1,18 @@
 package rubberring
 
+import "time"
+
 type config struct {
 	startChankSize        int
 	pasiveChankBufferSize int
 	startChankCount       int
 	growStrategy          GrowStrategy
+	visibilityTimeout     time.Duration
+	nackToFront           bool
+	maxRedeliveries       int
+	retention             int
+	ttl                   time.Duration
+	clock                 Clock
 }
 
 var defaultConfig = config{
@@ -12,6 +20,8 @@ var defaultConfig = config{
 	startChankCount:       4,
 	pasiveChankBufferSize: 3,
 	growStrategy:          func(capacity int) (int, int) { return 256, 4 },
+	visibilityTimeout:     30 * time.Second,
+	clock:                 realClock{},
 }
 
 type applyConfigFunc func(o *config)
@@ -48,3 +58,68 @@ func WithGrowStrategy(strategy GrowStrategy) applyConfigFunc {
 		c.growStrategy = strategy
 	}
 }
+
+// WithVisibilityTimeout sets how long a leased element of SyncRubberRing stays invisible
+// before it is redelivered (30 seconds by default)
+func WithVisibilityTimeout(timeout time.Duration) applyConfigFunc {
+	if timeout <= 0 {
+		timeout = defaultConfig.visibilityTimeout
+	}
+	return func(c *config) {
+		c.visibilityTimeout = timeout
+	}
+}
+
+// WithNackToFront makes redelivered elements of SyncRubberRing go before the queued ones,
+// by default they go after the elements pushed before the redelivery
+func WithNackToFront() applyConfigFunc {
+	return func(c *config) {
+		c.nackToFront = true
+	}
+}
+
+// WithMaxRedeliveries sets how many times an element of SyncRubberRing is redelivered
+// before it goes to the dead letter ring, 0 means no limit
+func WithMaxRedeliveries(count int) applyConfigFunc {
+	if count < 0 {
+		count = 0
+	}
+	return func(c *config) {
+		c.maxRedeliveries = count
+	}
+}
+
+// WithRetention keeps at least count pulled elements in the chunks, so they can be read again after SeekTo
+func WithRetention(count int) applyConfigFunc {
+	if count < 0 {
+		count = 0
+	}
+	return func(c *config) {
+		c.retention = count
+	}
+}
+
+// WithElementTTL makes Pull skip elements pushed more than ttl ago
+func WithElementTTL(ttl time.Duration) applyConfigFunc {
+	if ttl < 0 {
+		ttl = 0
+	}
+	return func(c *config) {
+		c.ttl = ttl
+	}
+}
+
+// withoutTTL is appended to the options of rings that keep their own count of the elements,
+// WithElementTTL is ignored there
+func withoutTTL() applyConfigFunc {
+	return func(c *config) {
+		c.ttl = 0
+	}
+}
+
+// WithClock replaces the clock used for TTL and lease timeouts
+func WithClock(clock Clock) applyConfigFunc {
+	return func(c *config) {
+		c.clock = clock
+	}
+}
@@ -0,0 +1,118 @@
+package rubberring
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// DedupRing rejects elements whose key is queued or was pulled within the last window elements.
+// The seen keys of the window are kept in a RubberRing, so the set is bounded by size + window.
+type DedupRing[K comparable, V any] struct {
+	ring   *SyncRubberRing[V]
+	key    func(V) K
+	window int
+
+	mu       *sync.Mutex
+	seen     map[K]struct{}
+	recent   *RubberRing[K]
+	rejected uint64
+}
+
+func NewDedupRing[K comparable, V any](key func(V) K, window int, options ...applyConfigFunc) *DedupRing[K, V] {
+	r := &DedupRing[K, V]{
+		ring:   NewSyncRubberRing[V](options...),
+		key:    key,
+		window: max(0, window),
+		mu:     &sync.Mutex{},
+		seen:   make(map[K]struct{}),
+		recent: NewRubberRing[K](WithStartChankSize(64), WithStartChankCount(1)),
+	}
+	// an element skipped because of TTL leaves the ring as a pulled one
+	r.ring.OnExpired(r.pulled)
+	return r
+}
+
+func (r *DedupRing[K, V]) Size() int {
+	return r.ring.Size()
+}
+
+func (r *DedupRing[K, V]) Stat() RubberRingStat {
+	return r.ring.Stat()
+}
+
+// Seen returns the number of keys that are rejected now
+func (r *DedupRing[K, V]) Seen() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.seen)
+}
+
+// Rejected returns the number of rejected pushes
+func (r *DedupRing[K, V]) Rejected() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rejected
+}
+
+// Push puts the element, it returns false if the key of the element is queued or seen within the window
+func (r *DedupRing[K, V]) Push(value V) bool {
+	key := r.key(value)
+	r.mu.Lock()
+	if _, ok := r.seen[key]; ok {
+		r.rejected++
+		r.mu.Unlock()
+		return false
+	}
+	r.seen[key] = struct{}{}
+	r.mu.Unlock()
+	r.ring.Push(value)
+	return true
+}
+
+func (r *DedupRing[K, V]) Pull(ctx context.Context) (V, error) {
+	v, err := r.ring.Pull(ctx)
+	if err == nil {
+		r.pulled(v)
+	}
+	return v, err
+}
+
+// TryPull returns io.EOF instead of waiting if the ring is empty
+func (r *DedupRing[K, V]) TryPull() (V, error) {
+	v, err := r.ring.TryPull()
+	if err == nil {
+		r.pulled(v)
+	}
+	return v, err
+}
+
+func (r *DedupRing[K, V]) Elements(ctx context.Context) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for {
+			v, err := r.Pull(ctx)
+			if err != nil {
+				return
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// pulled moves the key of the pulled element to the window
+func (r *DedupRing[K, V]) pulled(value V) {
+	key := r.key(value)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.window == 0 {
+		delete(r.seen, key)
+		return
+	}
+	r.recent.Push(key)
+	if r.recent.Size() > r.window {
+		old, _ := r.recent.Pull()
+		delete(r.seen, old)
+	}
+}
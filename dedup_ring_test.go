@@ -0,0 +1,104 @@
+package rubberring
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/goleak"
+)
+
+type DedupRingSuite struct {
+	suite.Suite
+	ring *DedupRing[string, string]
+}
+
+func (s *DedupRingSuite) SetupTest() {
+	s.ring = NewDedupRing[string, string](strings.ToLower, 2)
+}
+
+func (s *DedupRingSuite) TearDownTest() {
+	s.NoError(goleak.Find())
+	s.ring = nil
+}
+
+func (s *DedupRingSuite) TestRejectsQueued() {
+	s.True(s.ring.Push("a"))
+	s.True(s.ring.Push("b"))
+	s.False(s.ring.Push("A"))
+	s.Equal(2, s.ring.Size())
+	s.Equal(uint64(1), s.ring.Rejected())
+}
+
+func (s *DedupRingSuite) TestWindow() {
+	s.ring.Push("a")
+	s.ring.Push("b")
+	s.ring.Push("c")
+	for range 3 {
+		_, err := s.ring.TryPull()
+		s.NoError(err)
+	}
+	_, err := s.ring.TryPull()
+	s.Equal(io.EOF, err)
+
+	// only the last two pulled keys are remembered
+	s.Equal(2, s.ring.Seen())
+	s.True(s.ring.Push("a"))
+	s.False(s.ring.Push("b"))
+	s.False(s.ring.Push("c"))
+}
+
+func (s *DedupRingSuite) TestWithoutWindow() {
+	s.ring = NewDedupRing[string, string](strings.ToLower, 0)
+	s.ring.Push("a")
+	v, err := s.ring.Pull(context.Background())
+	s.NoError(err)
+	s.Equal("a", v)
+	s.Equal(0, s.ring.Seen())
+	s.True(s.ring.Push("a"))
+}
+
+func (s *DedupRingSuite) TestConcurrent() {
+	ring := NewDedupRing[int, int](func(v int) int { return v }, 1000)
+	wg := &sync.WaitGroup{}
+	accepted := make(chan int, 4000)
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				if ring.Push(i) {
+					accepted <- i
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(accepted)
+	s.Len(accepted, 1000)
+	s.Equal(1000, ring.Size())
+	s.Equal(uint64(3000), ring.Rejected())
+}
+
+func (s *DedupRingSuite) TestElementTTL() {
+	clock := newFakeClock()
+	s.ring = NewDedupRing[string, string](strings.ToLower, 0, WithElementTTL(time.Second), WithClock(clock))
+	s.True(s.ring.Push("a"))
+	clock.Advance(time.Second)
+	s.True(s.ring.Push("b"))
+
+	v, err := s.ring.TryPull()
+	s.NoError(err)
+	s.Equal("b", v)
+	// the key of the expired element is released as if it was pulled
+	s.Equal(0, s.ring.Seen())
+	s.True(s.ring.Push("A"))
+}
+
+func TestDedupRingSuite(t *testing.T) {
+	suite.Run(t, new(DedupRingSuite))
+}
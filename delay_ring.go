@@ -0,0 +1,180 @@
+package rubberring
+
+import (
+	"container/heap"
+	"context"
+	"iter"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for rings with deadlines, it can be replaced in tests
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type delayConfig struct {
+	readyOptions []applyConfigFunc
+}
+
+type applyDelayConfigFunc func(c *delayConfig)
+
+// WithReadyOptions passes options to the RubberRing that holds elements whose time has come,
+// the clock of WithClock is also used for the deadlines
+func WithReadyOptions(options ...applyConfigFunc) applyDelayConfigFunc {
+	return func(c *delayConfig) {
+		c.readyOptions = append(c.readyOptions, options...)
+	}
+}
+
+type delayedItem[V any] struct {
+	at    time.Time
+	seq   uint64
+	value V
+}
+
+type delayHeap[V any] []delayedItem[V]
+
+func (h delayHeap[V]) Len() int { return len(h) }
+func (h delayHeap[V]) Less(i, j int) bool {
+	if h[i].at.Equal(h[j].at) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].at.Before(h[j].at)
+}
+func (h delayHeap[V]) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *delayHeap[V]) Push(x any)   { *h = append(*h, x.(delayedItem[V])) }
+func (h *delayHeap[V]) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = delayedItem[V]{}
+	*h = old[:len(old)-1]
+	return item
+}
+
+// DelayRing returns elements only when their time has come, elements with the same time
+// are returned in the push order. Elements whose time has come are moved to a RubberRing.
+type DelayRing[V any] struct {
+	mu      *sync.Mutex
+	clock   Clock
+	delayed delayHeap[V]
+	ready   *RubberRing[V]
+	seq     uint64
+	// changed is closed and replaced when the earliest deadline changes, so waiters reset their timers
+	changed chan struct{}
+}
+
+func NewDelayRing[V any](options ...applyDelayConfigFunc) *DelayRing[V] {
+	config := delayConfig{}
+	for _, option := range options {
+		option(&config)
+	}
+	ready := NewRubberRing[V](config.readyOptions...)
+	return &DelayRing[V]{
+		mu:      &sync.Mutex{},
+		clock:   ready.config.clock,
+		ready:   ready,
+		changed: make(chan struct{}),
+	}
+}
+
+// Size returns the number of all elements, including the ones whose time has not come yet
+func (r *DelayRing[V]) Size() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ready.Size() + len(r.delayed)
+}
+
+// Next returns the time of the earliest element, false if the ring is empty
+func (r *DelayRing[V]) Next() (time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ready.Size() > 0 {
+		return r.clock.Now(), true
+	}
+	if len(r.delayed) == 0 {
+		return time.Time{}, false
+	}
+	return r.delayed[0].at, true
+}
+
+func (r *DelayRing[V]) PushAfter(d time.Duration, value V) {
+	r.PushAt(r.clock.Now().Add(d), value)
+}
+
+func (r *DelayRing[V]) PushAt(at time.Time, value V) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	heap.Push(&r.delayed, delayedItem[V]{at: at, seq: r.seq, value: value})
+	if r.delayed[0].seq == r.seq {
+		close(r.changed)
+		r.changed = make(chan struct{})
+	}
+}
+
+// TryPull returns io.EOF if there are no elements whose time has come
+func (r *DelayRing[V]) TryPull() (V, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.promoteLocked(r.clock.Now())
+	return r.ready.Pull()
+}
+
+// Pull waits until the time of the earliest element comes
+func (r *DelayRing[V]) Pull(ctx context.Context) (V, error) {
+	r.mu.Lock()
+	for {
+		now := r.clock.Now()
+		r.promoteLocked(now)
+		if v, err := r.ready.Pull(); err == nil {
+			r.mu.Unlock()
+			return v, nil
+		}
+		var timer <-chan time.Time
+		if len(r.delayed) > 0 {
+			timer = r.clock.After(r.delayed[0].at.Sub(now))
+		}
+		changed := r.changed
+		r.mu.Unlock()
+		select {
+		case <-changed:
+		case <-timer:
+		case <-ctx.Done():
+			var v V
+			return v, ctx.Err()
+		}
+		r.mu.Lock()
+	}
+}
+
+func (r *DelayRing[V]) Elements(ctx context.Context) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for {
+			v, err := r.Pull(ctx)
+			if err != nil {
+				return
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func (r *DelayRing[V]) promoteLocked(now time.Time) {
+	for len(r.delayed) > 0 && !r.delayed[0].at.After(now) {
+		r.ready.Push(heap.Pop(&r.delayed).(delayedItem[V]).value)
+	}
+}
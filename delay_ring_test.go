@@ -0,0 +1,157 @@
+package rubberring
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/goleak"
+)
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// fakeClock moves only by Advance and fires the timers whose time has come
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []fakeTimer
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1000, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.timers = append(c.timers, fakeTimer{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	timers := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.at.After(c.now) {
+			timers = append(timers, timer)
+			continue
+		}
+		timer.ch <- c.now
+	}
+	c.timers = timers
+}
+
+func (c *fakeClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+type DelayRingSuite struct {
+	suite.Suite
+	clock *fakeClock
+	ring  *DelayRing[int]
+}
+
+func (s *DelayRingSuite) SetupTest() {
+	s.clock = newFakeClock()
+	s.ring = NewDelayRing[int](WithReadyOptions(WithClock(s.clock)))
+}
+
+func (s *DelayRingSuite) TearDownTest() {
+	s.NoError(goleak.Find())
+	s.ring = nil
+}
+
+func (s *DelayRingSuite) TestOrder() {
+	s.ring.PushAfter(3*time.Second, 3)
+	s.ring.PushAfter(time.Second, 1)
+	s.ring.PushAfter(2*time.Second, 2)
+	s.ring.PushAfter(time.Second, 11)
+	s.Equal(4, s.ring.Size())
+
+	next, ok := s.ring.Next()
+	s.True(ok)
+	s.Equal(s.clock.Now().Add(time.Second), next)
+
+	_, err := s.ring.TryPull()
+	s.Equal(io.EOF, err)
+
+	s.clock.Advance(2 * time.Second)
+	result := []int{}
+	for {
+		v, err := s.ring.TryPull()
+		if err != nil {
+			break
+		}
+		result = append(result, v)
+	}
+	s.Equal([]int{1, 11, 2}, result)
+	s.Equal(1, s.ring.Size())
+}
+
+func (s *DelayRingSuite) TestPullWakesAtDeadline() {
+	s.ring.PushAfter(time.Minute, 2)
+
+	got := make(chan int)
+	go func() {
+		v, err := s.ring.Pull(context.Background())
+		s.NoError(err)
+		got <- v
+	}()
+	s.Eventually(func() bool { return s.clock.Timers() == 1 }, time.Second, time.Millisecond)
+
+	// an earlier element resets the timer of the waiter
+	s.ring.PushAfter(time.Second, 1)
+	s.Eventually(func() bool { return s.clock.Timers() == 2 }, time.Second, time.Millisecond)
+
+	s.clock.Advance(time.Second)
+	s.Equal(1, <-got)
+	s.Equal(1, s.ring.Size())
+}
+
+func (s *DelayRingSuite) TestPullCancel() {
+	s.ring.PushAfter(time.Minute, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := s.ring.Pull(ctx)
+	s.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func (s *DelayRingSuite) TestRealClock() {
+	ring := NewDelayRing[int]()
+	start := time.Now()
+	ring.PushAfter(30*time.Millisecond, 1)
+	ring.PushAt(start, 0)
+
+	v, err := ring.Pull(context.Background())
+	s.NoError(err)
+	s.Equal(0, v)
+	v, err = ring.Pull(context.Background())
+	s.NoError(err)
+	s.Equal(1, v)
+	s.GreaterOrEqual(time.Since(start), 30*time.Millisecond)
+}
+
+func TestDelayRingSuite(t *testing.T) {
+	suite.Run(t, new(DelayRingSuite))
+}
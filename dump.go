@@ -0,0 +1,278 @@
+package rubberring
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"iter"
+	"math"
+)
+
+// dump layout (little endian):
+//
+//	magic [4]byte | version uint8
+//	startChankSize uint32 | startChankCount uint32 | pasiveChankBufferSize uint32
+//	count uint64 | count * (length uint32 | encoded element)
+//	crc32 uint32 of everything above
+const dumpVersion = 1
+
+var dumpMagic = [4]byte{'R', 'B', 'R', 'G'}
+
+var ErrInvalidDump = errors.New("rubberring: invalid dump")
+
+func LoadRubberRing[V any](
+	r io.Reader,
+	codec Codec[V],
+	options ...applyConfigFunc,
+) (*RubberRing[V], error) {
+	config, elements, _, err := readDump(r, codec)
+	if err != nil {
+		return nil, err
+	}
+	ring := newRubberRing[V](config, options)
+	for _, el := range elements {
+		ring.Push(el)
+	}
+	return ring, nil
+}
+
+func LoadSyncRubberRing[V any](
+	r io.Reader,
+	codec Codec[V],
+	options ...applyConfigFunc,
+) (*SyncRubberRing[V], error) {
+	ring, err := LoadRubberRing(r, codec, options...)
+	if err != nil {
+		return nil, err
+	}
+	return newSyncRubberRing(ring), nil
+}
+
+func (r *RubberRing[V]) WriteTo(w io.Writer, codec Codec[V]) (int64, error) {
+	return writeDump(w, r.config, r.size, r.all(), codec)
+}
+
+// LoadFrom appends the elements of the dump to the end of the ring.
+// The ring stays unchanged if the dump can not be read.
+func (r *RubberRing[V]) LoadFrom(rd io.Reader, codec Codec[V]) (int64, error) {
+	_, elements, n, err := readDump(rd, codec)
+	if err != nil {
+		return n, err
+	}
+	for _, el := range elements {
+		r.Push(el)
+	}
+	return n, nil
+}
+
+// WriteTo also writes the elements in flight and the redelivered ones before the others,
+// so a dump taken on shutdown loses nothing
+func (r *SyncRubberRing[V]) WriteTo(w io.Writer, codec Codec[V]) (int64, error) {
+	r.lockAll()
+	defer r.unlockAll()
+	leased := r.appendLeasedLocked(nil, true)
+	return writeDump(w, r.ring.config, len(leased)+r.ring.size, func(yield func(V) bool) {
+		for _, v := range leased {
+			if !yield(v) {
+				return
+			}
+		}
+		r.ring.all()(yield)
+	}, codec)
+}
+
+func (r *SyncRubberRing[V]) LoadFrom(rd io.Reader, codec Codec[V]) (int64, error) {
+	_, elements, n, err := readDump(rd, codec)
+	if err != nil {
+		return n, err
+	}
+	r.lockAll()
+	for _, el := range elements {
+		r.ring.Push(el)
+	}
+	r.unlockAll()
+	for range elements {
+		r.signal()
+	}
+	return n, nil
+}
+
+type dumpWriter struct {
+	w   *bufio.Writer
+	crc hash.Hash32
+	n   int64
+	buf [8]byte
+}
+
+func (w *dumpWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.crc.Write(p[:n])
+	w.n += int64(n)
+	return n, err
+}
+
+func (w *dumpWriter) writeUint32(v uint32) error {
+	binary.LittleEndian.PutUint32(w.buf[:4], v)
+	_, err := w.Write(w.buf[:4])
+	return err
+}
+
+func (w *dumpWriter) writeUint64(v uint64) error {
+	binary.LittleEndian.PutUint64(w.buf[:], v)
+	_, err := w.Write(w.buf[:])
+	return err
+}
+
+func writeDump[V any](
+	w io.Writer,
+	config config,
+	size int,
+	elements iter.Seq[V],
+	codec Codec[V],
+) (int64, error) {
+	dw := &dumpWriter{w: bufio.NewWriter(w), crc: crc32.NewIEEE()}
+	header := append(dumpMagic[:], dumpVersion)
+	if _, err := dw.Write(header); err != nil {
+		return dw.n, err
+	}
+	for _, v := range []int{
+		config.startChankSize,
+		config.startChankCount,
+		config.pasiveChankBufferSize,
+	} {
+		if err := dw.writeUint32(uint32(v)); err != nil {
+			return dw.n, err
+		}
+	}
+	if err := dw.writeUint64(uint64(size)); err != nil {
+		return dw.n, err
+	}
+	var data []byte
+	for el := range elements {
+		var err error
+		data, err = codec.Encode(data[:0], el)
+		if err != nil {
+			return dw.n, err
+		}
+		if len(data) > math.MaxUint32 {
+			return dw.n, fmt.Errorf("rubberring: encoded element is too large: %d bytes", len(data))
+		}
+		if err := dw.writeUint32(uint32(len(data))); err != nil {
+			return dw.n, err
+		}
+		if _, err := dw.Write(data); err != nil {
+			return dw.n, err
+		}
+	}
+	binary.LittleEndian.PutUint32(dw.buf[:4], dw.crc.Sum32())
+	n, err := dw.w.Write(dw.buf[:4])
+	dw.n += int64(n)
+	if err != nil {
+		return dw.n, err
+	}
+	return dw.n, dw.w.Flush()
+}
+
+type dumpReader struct {
+	r   io.Reader
+	crc hash.Hash32
+	n   int64
+	buf [8]byte
+}
+
+func (r *dumpReader) read(p []byte) error {
+	n, err := io.ReadFull(r.r, p)
+	r.crc.Write(p[:n])
+	r.n += int64(n)
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// readN grows buf as the data arrives, so a corrupted length can not force a huge allocation
+func (r *dumpReader) readN(buf *bytes.Buffer, n int64) error {
+	start := buf.Len()
+	read, err := io.CopyN(buf, r.r, n)
+	r.crc.Write(buf.Bytes()[start:])
+	r.n += read
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func (r *dumpReader) readUint32() (uint32, error) {
+	err := r.read(r.buf[:4])
+	return binary.LittleEndian.Uint32(r.buf[:4]), err
+}
+
+func (r *dumpReader) readUint64() (uint64, error) {
+	err := r.read(r.buf[:])
+	return binary.LittleEndian.Uint64(r.buf[:]), err
+}
+
+// readDump reads exactly one dump from r, so several dumps can follow each other in one stream
+func readDump[V any](r io.Reader, codec Codec[V]) (config, []V, int64, error) {
+	config := defaultConfig
+	dr := &dumpReader{r: r, crc: crc32.NewIEEE()}
+	header := make([]byte, len(dumpMagic)+1)
+	if err := dr.read(header); err != nil {
+		return config, nil, dr.n, err
+	}
+	if [4]byte(header) != dumpMagic {
+		return config, nil, dr.n, ErrInvalidDump
+	}
+	if header[4] != dumpVersion {
+		return config, nil, dr.n, fmt.Errorf("%w: unsupported version %d", ErrInvalidDump, header[4])
+	}
+	for _, field := range []*int{
+		&config.startChankSize,
+		&config.startChankCount,
+		&config.pasiveChankBufferSize,
+	} {
+		v, err := dr.readUint32()
+		if err != nil {
+			return config, nil, dr.n, err
+		}
+		if v < 1 || v > math.MaxInt32 {
+			return config, nil, dr.n, fmt.Errorf("%w: bad configuration", ErrInvalidDump)
+		}
+		*field = int(v)
+	}
+	count, err := dr.readUint64()
+	if err != nil {
+		return config, nil, dr.n, err
+	}
+	elements := make([]V, 0, min(count, 1<<16))
+	var data bytes.Buffer
+	for range count {
+		length, err := dr.readUint32()
+		if err != nil {
+			return config, nil, dr.n, err
+		}
+		data.Reset()
+		if err := dr.readN(&data, int64(length)); err != nil {
+			return config, nil, dr.n, err
+		}
+		el, err := codec.Decode(data.Bytes())
+		if err != nil {
+			return config, nil, dr.n, err
+		}
+		elements = append(elements, el)
+	}
+	sum := dr.crc.Sum32()
+	checksum, err := dr.readUint32()
+	if err != nil {
+		return config, nil, dr.n, err
+	}
+	if checksum != sum {
+		return config, nil, dr.n, fmt.Errorf("%w: checksum mismatch", ErrInvalidDump)
+	}
+	return config, elements, dr.n, nil
+}
@@ -0,0 +1,159 @@
+package rubberring
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DumpSuite struct {
+	suite.Suite
+}
+
+func (s *DumpSuite) TestRoundTrip() {
+	ring := NewRubberRing[string](
+		WithStartChankSize(2),
+		WithStartChankCount(3),
+		WithPassiveChankBufferSize(5),
+	)
+	for _, v := range []string{"a", "b", "c", "d", "e"} {
+		ring.Push(v)
+	}
+	_, err := ring.Pull()
+	s.NoError(err)
+
+	buf := &bytes.Buffer{}
+	n, err := ring.WriteTo(buf, StringCodec())
+	s.NoError(err)
+	s.Equal(int64(buf.Len()), n)
+	s.Equal(4, ring.Size())
+
+	restored, err := LoadRubberRing(bytes.NewReader(buf.Bytes()), StringCodec())
+	s.NoError(err)
+	s.Equal([]string{"b", "c", "d", "e"}, restored.ToSlice())
+	s.Equal(2, restored.config.startChankSize)
+	s.Equal(3, restored.config.startChankCount)
+	s.Equal(5, restored.config.pasiveChankBufferSize)
+
+	restored, err = LoadRubberRing(
+		bytes.NewReader(buf.Bytes()),
+		StringCodec(),
+		WithStartChankSize(8),
+	)
+	s.NoError(err)
+	s.Equal(8, restored.config.startChankSize)
+}
+
+func (s *DumpSuite) TestLoadFromAppends() {
+	source := NewRubberRing[int64]()
+	source.Push(3)
+	source.Push(4)
+
+	buf := &bytes.Buffer{}
+	_, err := source.WriteTo(buf, NumericCodec[int64]())
+	s.NoError(err)
+	size := buf.Len()
+
+	target := NewRubberRing[int64]()
+	target.Push(1)
+	target.Push(2)
+	n, err := target.LoadFrom(buf, NumericCodec[int64]())
+	s.NoError(err)
+	s.Equal(int64(size), n)
+	s.Equal([]int64{1, 2, 3, 4}, target.ToSlice())
+}
+
+func (s *DumpSuite) TestConsecutiveDumps() {
+	buf := &bytes.Buffer{}
+	for i := range 2 {
+		ring := NewRubberRing[int32]()
+		ring.Push(int32(i))
+		_, err := ring.WriteTo(buf, NumericCodec[int32]())
+		s.NoError(err)
+	}
+
+	for i := range 2 {
+		ring, err := LoadRubberRing(buf, NumericCodec[int32]())
+		s.NoError(err)
+		s.Equal([]int32{int32(i)}, ring.ToSlice())
+	}
+	s.Zero(buf.Len())
+}
+
+func (s *DumpSuite) TestSyncRubberRing() {
+	ring := NewSyncRubberRing[codecTestStruct]()
+	ring.Push(codecTestStruct{Name: "a", Count: 1})
+	ring.Push(codecTestStruct{Name: "b", Count: 2})
+
+	buf := &bytes.Buffer{}
+	_, err := ring.WriteTo(buf, JSONCodec[codecTestStruct]())
+	s.NoError(err)
+
+	restored, err := LoadSyncRubberRing(bytes.NewReader(buf.Bytes()), JSONCodec[codecTestStruct]())
+	s.NoError(err)
+	s.Equal(ring.ToSlice(), restored.ToSlice())
+
+	target := NewSyncRubberRing[codecTestStruct]()
+	_, err = target.LoadFrom(bytes.NewReader(buf.Bytes()), JSONCodec[codecTestStruct]())
+	s.NoError(err)
+	v, err := target.Pull(context.Background())
+	s.NoError(err)
+	s.Equal("a", v.Name)
+}
+
+func (s *DumpSuite) TestInvalidDump() {
+	ring := NewRubberRing[string]()
+	ring.Push("a")
+	ring.Push("b")
+	buf := &bytes.Buffer{}
+	_, err := ring.WriteTo(buf, StringCodec())
+	s.NoError(err)
+	data := buf.Bytes()
+
+	_, err = LoadRubberRing(bytes.NewReader([]byte("JUNK!")), StringCodec())
+	s.ErrorIs(err, ErrInvalidDump)
+
+	_, err = LoadRubberRing(bytes.NewReader(data[:len(data)-3]), StringCodec())
+	s.ErrorIs(err, io.ErrUnexpectedEOF)
+
+	corrupted := bytes.Clone(data)
+	corrupted[len(corrupted)-5] ^= 0xff
+	_, err = LoadRubberRing(bytes.NewReader(corrupted), StringCodec())
+	s.ErrorIs(err, ErrInvalidDump)
+
+	corrupted = bytes.Clone(data)
+	corrupted[len(corrupted)-6] = 0xff
+	_, err = LoadRubberRing(bytes.NewReader(corrupted), StringCodec())
+	s.ErrorIs(err, io.ErrUnexpectedEOF)
+
+	_, err = ring.LoadFrom(bytes.NewReader(corrupted), StringCodec())
+	s.Error(err)
+	s.Equal([]string{"a", "b"}, ring.ToSlice())
+}
+
+func (s *DumpSuite) TestEncodeError() {
+	ring := NewRubberRing[int]()
+	ring.Push(1)
+	_, err := ring.WriteTo(&bytes.Buffer{}, failingCodec{})
+	s.ErrorIs(err, errFailingCodec)
+}
+
+var errFailingCodec = errors.New("failing codec")
+
+type failingCodec struct{}
+
+func (failingCodec) Encode(dst []byte, _ int) ([]byte, error) {
+	return dst, errFailingCodec
+}
+
+func (failingCodec) Decode([]byte) (int, error) {
+	return 0, errFailingCodec
+}
+
+func TestDumpSuite(t *testing.T) {
+	suite.Run(t, new(DumpSuite))
+}
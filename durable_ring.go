@@ -0,0 +1,602 @@
+package rubberring
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	syncutils "github.com/Skrip42/syncUtils"
+)
+
+// wal segment layout (little endian):
+//
+//	magic [4]byte | version uint8 | firstSeq uint64
+//	records:
+//	  push: walPushRecord uint8 | length uint32 | encoded element | crc32 uint32
+//	  pull: walPullRecord uint8 | seq uint64 | crc32 uint32
+//
+// every pushed element gets the next sequence number, a pull record stores the sequence number
+// of the pulled element, so the ring is rebuilt from the pushes that have no matching pull
+const (
+	walVersion       = 1
+	walHeaderSize    = 13
+	walPushRecord    = 1
+	walPullRecord    = 2
+	walSegmentSuffix = ".wal"
+)
+
+var walMagic = [4]byte{'R', 'B', 'W', 'L'}
+
+var (
+	ErrDurableRingClosed = errors.New("rubberring: durable ring is closed")
+	// ErrDurableRingFailed is returned after the log could not be written or synced,
+	// the ring has to be reopened to replay the log
+	ErrDurableRingFailed = errors.New("rubberring: durable ring failed")
+)
+
+type durableConfig struct {
+	fsyncBatch    int
+	fsyncInterval time.Duration
+	segmentSize   int64
+	ringOptions   []applyConfigFunc
+}
+
+var defaultDurableConfig = durableConfig{
+	fsyncBatch:  1,
+	segmentSize: 64 << 20,
+}
+
+type applyDurableConfigFunc func(c *durableConfig)
+
+// WithFsyncEveryPush syncs the log before every Push returns (default)
+func WithFsyncEveryPush() applyDurableConfigFunc {
+	return WithFsyncBatch(1)
+}
+
+// WithFsyncBatch syncs the log after every batchSize records
+func WithFsyncBatch(batchSize int) applyDurableConfigFunc {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	return func(c *durableConfig) {
+		c.fsyncBatch = batchSize
+	}
+}
+
+// WithFsyncInterval syncs the log in the background once per interval instead of on push,
+// a non-positive interval keeps the sync on every push
+func WithFsyncInterval(interval time.Duration) applyDurableConfigFunc {
+	if interval <= 0 {
+		return WithFsyncEveryPush()
+	}
+	return func(c *durableConfig) {
+		c.fsyncBatch = 0
+		c.fsyncInterval = interval
+	}
+}
+
+// WithSegmentSize sets the size after which a new log segment is started and
+// fully consumed segments are removed
+func WithSegmentSize(size int64) applyDurableConfigFunc {
+	if size < walHeaderSize {
+		size = walHeaderSize
+	}
+	return func(c *durableConfig) {
+		c.segmentSize = size
+	}
+}
+
+// WithRingOptions passes options to the RubberRing in memory, WithElementTTL is ignored,
+// the log keeps no deadlines
+func WithRingOptions(options ...applyConfigFunc) applyDurableConfigFunc {
+	return func(c *durableConfig) {
+		c.ringOptions = append(c.ringOptions, options...)
+	}
+}
+
+type walSegment struct {
+	no       uint64
+	path     string
+	firstSeq uint64
+	// nextSeq is the sequence number following the last push in the segment
+	nextSeq uint64
+}
+
+type DurableRing[V any] struct {
+	ring     *RubberRing[V]
+	cond     *syncutils.Cond
+	mu       *sync.Mutex
+	codec    Codec[V]
+	config   durableConfig
+	dir      string
+	segments []walSegment
+	file     *os.File
+	fileSize int64
+	unsynced int
+	headSeq  uint64
+	nextSeq  uint64
+	buf      []byte
+	closed   bool
+	// err is set once the log does not match the ring anymore, all writes return it
+	err  error
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// OpenDurableRing replays the log in dir (created if missing) and opens a new segment for writing
+func OpenDurableRing[V any](
+	dir string,
+	codec Codec[V],
+	options ...applyDurableConfigFunc,
+) (*DurableRing[V], error) {
+	config := defaultDurableConfig
+	for _, option := range options {
+		option(&config)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	r := &DurableRing[V]{
+		ring:   NewRubberRing[V](append(config.ringOptions, withoutTTL())...),
+		cond:   syncutils.NewCond(),
+		mu:     &sync.Mutex{},
+		codec:  codec,
+		config: config,
+		dir:    dir,
+		done:   make(chan struct{}),
+	}
+	if err := r.replay(); err != nil {
+		return nil, err
+	}
+	if err := r.compact(); err != nil {
+		return nil, err
+	}
+	if err := r.openSegment(); err != nil {
+		return nil, err
+	}
+	if config.fsyncInterval > 0 {
+		r.wg.Add(1)
+		go r.syncLoop()
+	}
+	return r, nil
+}
+
+func (r *DurableRing[V]) Size() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ring.Size()
+}
+
+func (r *DurableRing[V]) Capacity() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ring.Capacity()
+}
+
+func (r *DurableRing[V]) Stat() RubberRingStat {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return stat(r.ring)
+}
+
+// Push returns after the element is written to the log (and synced, depending on the fsync policy)
+func (r *DurableRing[V]) Push(value V) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrDurableRingClosed
+	}
+
+	var err error
+	r.buf = append(r.buf[:0], walPushRecord, 0, 0, 0, 0)
+	r.buf, err = r.codec.Encode(r.buf, value)
+	if err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(r.buf[1:5], uint32(len(r.buf)-5))
+	if err := r.appendRecord(); err != nil {
+		return err
+	}
+	r.nextSeq++
+	r.segments[len(r.segments)-1].nextSeq = r.nextSeq
+	r.ring.Push(value)
+	r.cond.Signal()
+	return nil
+}
+
+// Pull records the consumer progress in the log before the element is removed from the ring
+func (r *DurableRing[V]) Pull(ctx context.Context) (V, error) {
+	var v V
+	r.mu.Lock()
+	for {
+		if r.closed {
+			r.mu.Unlock()
+			return v, ErrDurableRingClosed
+		}
+		if r.ring.Size() > 0 {
+			v, err := r.pullLocked()
+			r.mu.Unlock()
+			return v, err
+		}
+		wait := r.cond.Wait()
+		r.mu.Unlock()
+		select {
+		case <-wait:
+		case <-r.done:
+		case <-ctx.Done():
+			return v, ctx.Err()
+		}
+		r.mu.Lock()
+	}
+}
+
+func (r *DurableRing[V]) pullLocked() (V, error) {
+	var v V
+	r.buf = append(r.buf[:0], walPullRecord)
+	r.buf = binary.LittleEndian.AppendUint64(r.buf, r.headSeq)
+	if err := r.appendRecord(); err != nil {
+		return v, err
+	}
+	r.headSeq++
+	return r.ring.Pull()
+}
+
+func (r *DurableRing[V]) Elements(ctx context.Context) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for {
+			v, err := r.Pull(ctx)
+			if err != nil {
+				return
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Sync flushes all written records to stable storage
+func (r *DurableRing[V]) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrDurableRingClosed
+	}
+	if r.err != nil {
+		return r.err
+	}
+	return r.syncLocked()
+}
+
+func (r *DurableRing[V]) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	close(r.done)
+	err := r.syncLocked()
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	r.mu.Unlock()
+	r.wg.Wait()
+	return err
+}
+
+func (r *DurableRing[V]) syncLoop() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.config.fsyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.mu.Lock()
+			if !r.closed {
+				r.syncLocked()
+			}
+			r.mu.Unlock()
+		}
+	}
+}
+
+// syncLocked fails the ring if the sync fails, as the written records may be lost or not
+func (r *DurableRing[V]) syncLocked() error {
+	if r.unsynced == 0 {
+		return nil
+	}
+	if err := r.file.Sync(); err != nil {
+		return r.fail(err)
+	}
+	r.unsynced = 0
+	return nil
+}
+
+func (r *DurableRing[V]) fail(err error) error {
+	if r.err == nil {
+		r.err = fmt.Errorf("%w: %w", ErrDurableRingFailed, err)
+	}
+	return r.err
+}
+
+// appendRecord writes the record prepared in r.buf, a partly written record is cut off,
+// so the next records do not follow a torn one
+func (r *DurableRing[V]) appendRecord() error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.fileSize+int64(len(r.buf))+4 > r.config.segmentSize && r.fileSize > walHeaderSize {
+		if err := r.rotate(); err != nil {
+			return r.fail(err)
+		}
+	}
+	r.buf = binary.LittleEndian.AppendUint32(r.buf, crc32.ChecksumIEEE(r.buf))
+	n, err := r.file.Write(r.buf)
+	if err != nil {
+		if n > 0 {
+			if truncErr := r.file.Truncate(r.fileSize); truncErr != nil {
+				return r.fail(truncErr)
+			}
+			if _, seekErr := r.file.Seek(r.fileSize, io.SeekStart); seekErr != nil {
+				return r.fail(seekErr)
+			}
+		}
+		return err
+	}
+	r.fileSize += int64(n)
+	r.unsynced++
+	if r.config.fsyncBatch > 0 && r.buf[0] == walPushRecord && r.unsynced >= r.config.fsyncBatch {
+		return r.syncLocked()
+	}
+	return nil
+}
+
+func (r *DurableRing[V]) rotate() error {
+	if err := r.syncLocked(); err != nil {
+		return err
+	}
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	if err := r.openSegment(); err != nil {
+		return err
+	}
+	return r.compact()
+}
+
+func (r *DurableRing[V]) openSegment() error {
+	var no uint64 = 1
+	if len(r.segments) > 0 {
+		no = r.segments[len(r.segments)-1].no + 1
+	}
+	segment := walSegment{
+		no:       no,
+		path:     filepath.Join(r.dir, fmt.Sprintf("%020d%s", no, walSegmentSuffix)),
+		firstSeq: r.nextSeq,
+		nextSeq:  r.nextSeq,
+	}
+	file, err := os.OpenFile(segment.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	header := append(walMagic[:], walVersion)
+	header = binary.LittleEndian.AppendUint64(header, segment.firstSeq)
+	if _, err := file.Write(header); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := syncDir(r.dir); err != nil {
+		file.Close()
+		return err
+	}
+	r.file = file
+	r.fileSize = int64(len(header))
+	r.segments = append(r.segments, segment)
+	return nil
+}
+
+// compact removes the oldest closed segments whose elements are all consumed
+func (r *DurableRing[V]) compact() error {
+	removed := 0
+	for _, segment := range r.segments {
+		if r.file != nil && segment.path == r.file.Name() {
+			break
+		}
+		if segment.nextSeq > r.headSeq {
+			break
+		}
+		if err := os.Remove(segment.path); err != nil {
+			return err
+		}
+		removed++
+	}
+	r.segments = slices.Delete(r.segments, 0, removed)
+	return nil
+}
+
+func (r *DurableRing[V]) replay() error {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return err
+	}
+	var segments []walSegment
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, walSegmentSuffix) {
+			continue
+		}
+		no, err := strconv.ParseUint(strings.TrimSuffix(name, walSegmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, walSegment{no: no, path: filepath.Join(r.dir, name)})
+	}
+
+	var firstSeq, consumed uint64
+	first := true
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		if info, err := os.Stat(segment.path); err == nil && last && info.Size() < walHeaderSize {
+			// crashed while the segment was being created
+			if err := os.Remove(segment.path); err != nil {
+				return err
+			}
+			continue
+		}
+		segment, err = r.replaySegment(segment, last, func(seq uint64, v V) error {
+			if first {
+				firstSeq = seq
+				first = false
+			}
+			if seq != firstSeq+uint64(r.ring.Size()) {
+				return fmt.Errorf("%w: sequence gap in %s", ErrInvalidDump, segment.path)
+			}
+			r.ring.Push(v)
+			return nil
+		}, func(seq uint64) {
+			consumed = max(consumed, seq+1)
+		})
+		if err != nil {
+			return err
+		}
+		r.segments = append(r.segments, segment)
+		r.nextSeq = segment.nextSeq
+	}
+
+	if first {
+		r.headSeq = max(consumed, r.nextSeq)
+		return nil
+	}
+	r.headSeq = max(consumed, firstSeq)
+	for seq := firstSeq; seq < r.headSeq; seq++ {
+		if _, err := r.ring.Pull(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaySegment reads all records of the segment, a torn record at the end of the last segment
+// (left by a crash in the middle of a write) is cut off
+func (r *DurableRing[V]) replaySegment(
+	segment walSegment,
+	last bool,
+	onPush func(seq uint64, v V) error,
+	onPull func(seq uint64),
+) (walSegment, error) {
+	file, err := os.OpenFile(segment.path, os.O_RDWR, 0)
+	if err != nil {
+		return segment, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return segment, err
+	}
+	reader := bufio.NewReader(file)
+
+	header := make([]byte, walHeaderSize)
+	if _, err := io.ReadFull(reader, header); err != nil || [4]byte(header) != walMagic {
+		return segment, fmt.Errorf("%w: bad segment header in %s", ErrInvalidDump, segment.path)
+	}
+	if header[4] != walVersion {
+		return segment, fmt.Errorf("%w: unsupported version %d in %s", ErrInvalidDump, header[4], segment.path)
+	}
+	segment.firstSeq = binary.LittleEndian.Uint64(header[5:])
+	segment.nextSeq = segment.firstSeq
+
+	offset := int64(walHeaderSize)
+	var record []byte
+	for {
+		var ok bool
+		record, ok = readWalRecord(reader, record[:0], info.Size()-offset)
+		if !ok {
+			break
+		}
+		switch record[0] {
+		case walPushRecord:
+			v, err := r.codec.Decode(record[5 : len(record)-4])
+			if err != nil {
+				return segment, err
+			}
+			if err := onPush(segment.nextSeq, v); err != nil {
+				return segment, err
+			}
+			segment.nextSeq++
+		case walPullRecord:
+			onPull(binary.LittleEndian.Uint64(record[1:9]))
+		}
+		offset += int64(len(record))
+	}
+	if info.Size() > offset {
+		if !last {
+			return segment, fmt.Errorf("%w: corrupted record in %s at %d", ErrInvalidDump, segment.path, offset)
+		}
+		if err := file.Truncate(offset); err != nil {
+			return segment, err
+		}
+	}
+	return segment, nil
+}
+
+// readWalRecord reads a record no longer than limit bytes and reports whether it is complete and intact
+func readWalRecord(reader *bufio.Reader, record []byte, limit int64) ([]byte, bool) {
+	kind, err := reader.ReadByte()
+	if err != nil {
+		return record, false
+	}
+	record = append(record, kind)
+	var size int
+	switch kind {
+	case walPushRecord:
+		head := make([]byte, 4)
+		if _, err := io.ReadFull(reader, head); err != nil {
+			return record, false
+		}
+		record = append(record, head...)
+		size = int(binary.LittleEndian.Uint32(head))
+	case walPullRecord:
+		size = 8
+	default:
+		return record, false
+	}
+	start := len(record)
+	if int64(start+size+4) > limit {
+		return record, false
+	}
+	record = slices.Grow(record, size+4)[:start+size+4]
+	if _, err := io.ReadFull(reader, record[start:]); err != nil {
+		return record, false
+	}
+	body := record[:len(record)-4]
+	return record, binary.LittleEndian.Uint32(record[len(record)-4:]) == crc32.ChecksumIEEE(body)
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,251 @@
+package rubberring
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/goleak"
+)
+
+type DurableRingSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (s *DurableRingSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+}
+
+func (s *DurableRingSuite) TearDownTest() {
+	s.NoError(goleak.Find())
+}
+
+func (s *DurableRingSuite) open(options ...applyDurableConfigFunc) *DurableRing[string] {
+	ring, err := OpenDurableRing(s.dir, StringCodec(), options...)
+	s.Require().NoError(err)
+	return ring
+}
+
+func (s *DurableRingSuite) pullAll(ring *DurableRing[string]) []string {
+	var result []string
+	for ring.Size() > 0 {
+		v, err := ring.Pull(context.Background())
+		s.Require().NoError(err)
+		result = append(result, v)
+	}
+	return result
+}
+
+func (s *DurableRingSuite) segments() []string {
+	segments, err := filepath.Glob(filepath.Join(s.dir, "*"+walSegmentSuffix))
+	s.Require().NoError(err)
+	return segments
+}
+
+func (s *DurableRingSuite) TestReplay() {
+	ring := s.open()
+	for _, v := range []string{"a", "b", "c", "d"} {
+		s.NoError(ring.Push(v))
+	}
+	v, err := ring.Pull(context.Background())
+	s.NoError(err)
+	s.Equal("a", v)
+	s.NoError(ring.Close())
+
+	ring = s.open()
+	s.Equal(3, ring.Size())
+	v, err = ring.Pull(context.Background())
+	s.NoError(err)
+	s.Equal("b", v)
+	s.NoError(ring.Push("e"))
+	s.NoError(ring.Close())
+
+	ring = s.open()
+	s.Equal([]string{"c", "d", "e"}, s.pullAll(ring))
+	s.NoError(ring.Close())
+
+	ring = s.open()
+	s.Equal(0, ring.Size())
+	s.NoError(ring.Push("f"))
+	s.NoError(ring.Close())
+
+	ring = s.open()
+	s.Equal([]string{"f"}, s.pullAll(ring))
+	s.NoError(ring.Close())
+}
+
+func (s *DurableRingSuite) TestTornRecord() {
+	ring := s.open()
+	s.NoError(ring.Push("a"))
+	s.NoError(ring.Push("b"))
+	s.NoError(ring.Close())
+
+	segments := s.segments()
+	last := segments[len(segments)-1]
+	f, err := os.OpenFile(last, os.O_APPEND|os.O_WRONLY, 0)
+	s.Require().NoError(err)
+	_, err = f.Write([]byte{walPushRecord, 10, 0, 0, 0, 'c'})
+	s.NoError(err)
+	s.NoError(f.Close())
+
+	ring = s.open()
+	s.Equal([]string{"a", "b"}, s.pullAll(ring))
+	s.NoError(ring.Close())
+
+	ring = s.open()
+	s.Equal(0, ring.Size())
+	s.NoError(ring.Close())
+}
+
+func (s *DurableRingSuite) TestCorruptedSegment() {
+	ring := s.open()
+	s.NoError(ring.Push("a"))
+	s.NoError(ring.Close())
+	ring = s.open()
+	s.NoError(ring.Close())
+
+	segments := s.segments()
+	s.Require().Len(segments, 2)
+	data, err := os.ReadFile(segments[0])
+	s.Require().NoError(err)
+	data[len(data)-1] ^= 0xff
+	s.NoError(os.WriteFile(segments[0], data, 0o644))
+
+	_, err = OpenDurableRing(s.dir, StringCodec())
+	s.ErrorIs(err, ErrInvalidDump)
+}
+
+func (s *DurableRingSuite) TestCompaction() {
+	ring := s.open(WithSegmentSize(64), WithFsyncBatch(16))
+	for i := range 100 {
+		s.NoError(ring.Push(string(rune('a' + i%26))))
+		if i%2 == 1 {
+			_, err := ring.Pull(context.Background())
+			s.NoError(err)
+		}
+	}
+	s.Equal(50, ring.Size())
+	s.NoError(ring.Close())
+
+	ring = s.open(WithSegmentSize(64))
+	s.Len(s.pullAll(ring), 50)
+	for range 10 {
+		s.NoError(ring.Push("x"))
+		_, err := ring.Pull(context.Background())
+		s.NoError(err)
+	}
+	s.LessOrEqual(len(s.segments()), 3)
+	s.NoError(ring.Close())
+
+	ring = s.open()
+	s.Equal(0, ring.Size())
+	s.NoError(ring.Close())
+}
+
+func (s *DurableRingSuite) TestFsyncInterval() {
+	ring := s.open(WithFsyncInterval(time.Millisecond))
+	s.NoError(ring.Push("a"))
+	time.Sleep(10 * time.Millisecond)
+	s.NoError(ring.Sync())
+	s.NoError(ring.Close())
+
+	ring = s.open()
+	s.Equal([]string{"a"}, s.pullAll(ring))
+	s.NoError(ring.Close())
+}
+
+func (s *DurableRingSuite) TestNonPositiveFsyncInterval() {
+	ring := s.open(WithFsyncBatch(8), WithFsyncInterval(0))
+	s.NoError(ring.Push("a"))
+	s.Equal(0, ring.unsynced)
+	s.NoError(ring.Close())
+}
+
+func (s *DurableRingSuite) TestPullWaitsAndClose() {
+	ring := s.open(WithRingOptions(WithStartChankSize(2)))
+
+	pulled := make(chan string)
+	go func() {
+		v, _ := ring.Pull(context.Background())
+		pulled <- v
+	}()
+	time.Sleep(10 * time.Millisecond)
+	s.NoError(ring.Push("a"))
+	s.Equal("a", <-pulled)
+
+	errs := make(chan error)
+	go func() {
+		_, err := ring.Pull(context.Background())
+		errs <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	s.NoError(ring.Close())
+	s.ErrorIs(<-errs, ErrDurableRingClosed)
+	s.ErrorIs(ring.Push("b"), ErrDurableRingClosed)
+}
+
+func (s *DurableRingSuite) TestElementTTLIgnored() {
+	clock := newFakeClock()
+	ring := s.open(WithRingOptions(WithElementTTL(time.Second), WithClock(clock)))
+	s.NoError(ring.Push("a"))
+	s.NoError(ring.Push("b"))
+	clock.Advance(time.Hour)
+	s.Equal([]string{"a", "b"}, s.pullAll(ring))
+	s.NoError(ring.Close())
+
+	ring = s.open()
+	s.Equal(0, ring.Size())
+	s.NoError(ring.Close())
+}
+
+func (s *DurableRingSuite) TestWriteFailure() {
+	ring := s.open()
+	s.NoError(ring.Push("a"))
+	file := ring.file
+	readOnly, err := os.Open(file.Name())
+	s.Require().NoError(err)
+	ring.file = readOnly
+	s.Error(ring.Push("b"))
+	s.NoError(readOnly.Close())
+
+	// nothing is written, so the ring goes on
+	ring.file = file
+	s.NoError(ring.Push("c"))
+	s.NoError(ring.Close())
+
+	ring = s.open()
+	s.Equal([]string{"a", "c"}, s.pullAll(ring))
+	s.NoError(ring.Close())
+}
+
+func (s *DurableRingSuite) TestSyncFailure() {
+	ring := s.open()
+	s.NoError(ring.Push("a"))
+	file := ring.file
+	reader, writer, err := os.Pipe()
+	s.Require().NoError(err)
+	defer reader.Close()
+	defer writer.Close()
+
+	// a pipe takes the record, but can not be synced
+	ring.file = writer
+	s.ErrorIs(ring.Push("b"), ErrDurableRingFailed)
+	s.ErrorIs(ring.Push("c"), ErrDurableRingFailed)
+	_, err = ring.Pull(context.Background())
+	s.ErrorIs(err, ErrDurableRingFailed)
+	s.ErrorIs(ring.Sync(), ErrDurableRingFailed)
+
+	ring.file = file
+	s.NoError(ring.Close())
+	ring = s.open()
+	s.Equal([]string{"a"}, s.pullAll(ring))
+	s.NoError(ring.Close())
+}
+
+func TestDurableRingSuite(t *testing.T) {
+	suite.Run(t, new(DurableRingSuite))
+}
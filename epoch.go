@@ -0,0 +1,50 @@
+package rubberring
+
+import "sync/atomic"
+
+// epochGuard is a minimal epoch based reclamation scheme: an object unlinked from the ring
+// while the epoch was e can be reused once the epoch reaches e+2, because every goroutine that
+// could still see it was pinned in epoch e or earlier
+type epochGuard struct {
+	epoch  atomic.Uint64
+	active [2]atomic.Int64
+}
+
+// epochReady is the epoch of objects that have never been published
+const epochReady = 0
+
+func newEpochGuard() *epochGuard {
+	guard := &epochGuard{}
+	guard.epoch.Store(epochReady + 2)
+	return guard
+}
+
+func (g *epochGuard) pin() uint64 {
+	for {
+		epoch := g.epoch.Load()
+		g.active[epoch&1].Add(1)
+		if g.epoch.Load() == epoch {
+			return epoch
+		}
+		g.active[epoch&1].Add(-1)
+	}
+}
+
+func (g *epochGuard) unpin(epoch uint64) {
+	g.active[epoch&1].Add(-1)
+}
+
+// tryAdvance moves to the next epoch if nobody is pinned in the previous one
+// (it shares the counter with the next epoch) and returns the current epoch
+func (g *epochGuard) tryAdvance() uint64 {
+	epoch := g.epoch.Load()
+	if g.active[(epoch+1)&1].Load() == 0 {
+		g.epoch.CompareAndSwap(epoch, epoch+1)
+	}
+	return g.epoch.Load()
+}
+
+// safe reports whether an object retired in the given epoch can be reused
+func (g *epochGuard) safe(retired uint64) bool {
+	return retired+2 <= g.tryAdvance()
+}
@@ -0,0 +1,256 @@
+package rubberring
+
+import (
+	"context"
+	"io"
+	"iter"
+	"slices"
+	"sync"
+
+	syncutils "github.com/Skrip42/syncUtils"
+)
+
+type fairConfig struct {
+	quantum       int
+	poolSize      int
+	tenantOptions []applyConfigFunc
+}
+
+type applyFairConfigFunc func(c *fairConfig)
+
+// WithQuantum sets how many elements a tenant with weight 1 gets per round (1 by default)
+func WithQuantum(quantum int) applyFairConfigFunc {
+	if quantum < 1 {
+		quantum = 1
+	}
+	return func(c *fairConfig) {
+		c.quantum = quantum
+	}
+}
+
+// WithSharedPoolSize sets how many passive chunks are kept for all tenants (64 by default)
+func WithSharedPoolSize(size int) applyFairConfigFunc {
+	if size < 1 {
+		size = 1
+	}
+	return func(c *fairConfig) {
+		c.poolSize = size
+	}
+}
+
+// WithTenantOptions passes options to the RubberRing of every tenant
+func WithTenantOptions(options ...applyConfigFunc) applyFairConfigFunc {
+	return func(c *fairConfig) {
+		c.tenantOptions = append(c.tenantOptions, options...)
+	}
+}
+
+type FairRingStat[K comparable] struct {
+	Size            int
+	Tenants         map[K]RubberRingStat
+	PassiveChanks   int
+	PassiveCapacity int
+}
+
+type fairTenant[K comparable, V any] struct {
+	key     K
+	ring    *RubberRing[V]
+	deficit int
+}
+
+// FairRing keeps a RubberRing per tenant key and serves tenants with deficit round robin,
+// so a noisy tenant does not delay the others. Rings of tenants are created on the first element
+// and released as soon as they are drained, their chunks go to a pool shared by all tenants.
+type FairRing[K comparable, V any] struct {
+	mu      *sync.Mutex
+	cond    *syncutils.Cond
+	config  config
+	quantum int
+	pool    chan *chank[V]
+	tenants map[K]*fairTenant[K, V]
+	weights map[K]int
+	active  []*fairTenant[K, V]
+	current int
+	size    int
+}
+
+func NewFairRing[K comparable, V any](options ...applyFairConfigFunc) *FairRing[K, V] {
+	fc := fairConfig{
+		quantum:  1,
+		poolSize: 64,
+	}
+	for _, option := range options {
+		option(&fc)
+	}
+	ringConfig := defaultConfig
+	ringConfig.startChankCount = 1
+	ringConfig.growStrategy = nil
+	for _, option := range fc.tenantOptions {
+		option(&ringConfig)
+	}
+	if ringConfig.growStrategy == nil {
+		// tenants grow by one chunk at a time, so chunks of the shared pool fit any tenant
+		chankSize := ringConfig.startChankSize
+		ringConfig.growStrategy = func(int) (int, int) { return chankSize, 1 }
+	}
+	return &FairRing[K, V]{
+		mu:      &sync.Mutex{},
+		cond:    syncutils.NewCond(),
+		config:  ringConfig,
+		quantum: fc.quantum,
+		pool:    make(chan *chank[V], fc.poolSize),
+		tenants: make(map[K]*fairTenant[K, V]),
+		weights: make(map[K]int),
+	}
+}
+
+// SetWeight sets the share of the tenant, a tenant with weight 2 gets twice as many elements
+// per round as a tenant with weight 1
+func (r *FairRing[K, V]) SetWeight(key K, weight int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if weight <= 1 {
+		delete(r.weights, key)
+		return
+	}
+	r.weights[key] = weight
+}
+
+func (r *FairRing[K, V]) Size() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.size
+}
+
+// Tenants returns the number of tenants with elements
+func (r *FairRing[K, V]) Tenants() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.active)
+}
+
+func (r *FairRing[K, V]) Stat() FairRingStat[K] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stat := FairRingStat[K]{
+		Size:          r.size,
+		Tenants:       make(map[K]RubberRingStat, len(r.tenants)),
+		PassiveChanks: len(r.pool),
+	}
+	for key, tenant := range r.tenants {
+		stat.Tenants[key] = tenant.ring.Stat()
+	}
+	for range len(r.pool) {
+		chk := <-r.pool
+		stat.PassiveCapacity += len(chk.data)
+		r.pool <- chk
+	}
+	return stat
+}
+
+func (r *FairRing[K, V]) Push(key K, value V) {
+	r.mu.Lock()
+	tenant, ok := r.tenants[key]
+	if !ok {
+		tenant = &fairTenant[K, V]{
+			key:  key,
+			ring: newPooledRubberRing(r.config, r.pool),
+		}
+		r.tenants[key] = tenant
+		r.active = append(r.active, tenant)
+	}
+	tenant.ring.Push(value)
+	r.size++
+	r.cond.Signal()
+	r.mu.Unlock()
+}
+
+// TryPull returns io.EOF instead of waiting if the ring is empty
+func (r *FairRing[K, V]) TryPull() (K, V, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if key, v, ok := r.pullLocked(); ok {
+		return key, v, nil
+	}
+	var key K
+	var v V
+	return key, v, io.EOF
+}
+
+// Pull waits for an element and returns it with the key of its tenant
+func (r *FairRing[K, V]) Pull(ctx context.Context) (K, V, error) {
+	var key K
+	var v V
+	r.mu.Lock()
+	for {
+		if key, v, ok := r.pullLocked(); ok {
+			r.mu.Unlock()
+			return key, v, nil
+		}
+		wait := r.cond.Wait()
+		r.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			select {
+			case <-wait:
+				// the signal was meant for an element, pass it to another waiter
+				r.mu.Lock()
+				r.cond.Signal()
+				r.mu.Unlock()
+			default:
+			}
+			return key, v, ctx.Err()
+		}
+		r.mu.Lock()
+	}
+}
+
+func (r *FairRing[K, V]) Elements(ctx context.Context) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for {
+			key, v, err := r.Pull(ctx)
+			if err != nil {
+				return
+			}
+			if !yield(key, v) {
+				return
+			}
+		}
+	}
+}
+
+// pullLocked must be called with mu held, it returns false if no element is left,
+// the size follows the tenant rings, so the elements skipped because of TTL are counted too
+func (r *FairRing[K, V]) pullLocked() (K, V, bool) {
+	for r.size > 0 {
+		tenant := r.active[r.current]
+		if tenant.deficit <= 0 {
+			// the tenant starts its turn
+			tenant.deficit += r.quantum * max(1, r.weights[tenant.key])
+		}
+		size := tenant.ring.Size()
+		v, err := tenant.ring.Pull()
+		r.size -= size - tenant.ring.Size()
+		if err == nil {
+			tenant.deficit--
+		}
+
+		if tenant.ring.Size() == 0 {
+			tenant.ring.release()
+			delete(r.tenants, tenant.key)
+			r.active = slices.Delete(r.active, r.current, r.current+1)
+		} else if tenant.deficit <= 0 {
+			r.current++
+		}
+		if r.current >= len(r.active) {
+			r.current = 0
+		}
+		if err == nil {
+			return tenant.key, v, true
+		}
+	}
+	var key K
+	var v V
+	return key, v, false
+}
@@ -0,0 +1,176 @@
+package rubberring
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/goleak"
+)
+
+type FairRingSuite struct {
+	suite.Suite
+	ring *FairRing[string, int]
+}
+
+func (s *FairRingSuite) SetupTest() {
+	s.ring = NewFairRing[string, int](
+		WithTenantOptions(WithStartChankSize(4)),
+		WithSharedPoolSize(8),
+	)
+}
+
+func (s *FairRingSuite) TearDownTest() {
+	s.NoError(goleak.Find())
+	s.ring = nil
+}
+
+func (s *FairRingSuite) pullKeys(n int) []string {
+	keys := make([]string, 0, n)
+	for range n {
+		key, _, err := s.ring.TryPull()
+		s.NoError(err)
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (s *FairRingSuite) TestNoisyTenant() {
+	for i := range 100 {
+		s.ring.Push("noisy", i)
+	}
+	s.ring.Push("quiet", 0)
+	s.ring.Push("quiet", 1)
+	s.Equal(2, s.ring.Tenants())
+
+	s.Equal([]string{"noisy", "quiet", "noisy", "quiet", "noisy", "noisy"}, s.pullKeys(6))
+	s.Equal(1, s.ring.Tenants())
+	s.Equal(96, s.ring.Size())
+}
+
+func (s *FairRingSuite) TestWeights() {
+	s.ring.SetWeight("a", 3)
+	for i := range 10 {
+		s.ring.Push("a", i)
+		s.ring.Push("b", i)
+	}
+	s.Equal([]string{"a", "a", "a", "b", "a", "a", "a", "b"}, s.pullKeys(8))
+}
+
+func (s *FairRingSuite) TestOrderInsideTenant() {
+	for i := range 20 {
+		s.ring.Push("a", i)
+		s.ring.Push("b", 100+i)
+	}
+	a, b := []int{}, []int{}
+	for range 40 {
+		key, v, err := s.ring.TryPull()
+		s.NoError(err)
+		if key == "a" {
+			a = append(a, v)
+		} else {
+			b = append(b, v)
+		}
+	}
+	for i := range 20 {
+		s.Equal(i, a[i])
+		s.Equal(100+i, b[i])
+	}
+	_, _, err := s.ring.TryPull()
+	s.Equal(io.EOF, err)
+}
+
+func (s *FairRingSuite) TestTenantChanksAreShared() {
+	for i := range 12 {
+		s.ring.Push("a", i)
+	}
+	stat := s.ring.Stat()
+	s.Equal(12, stat.Size)
+	s.Equal(16, stat.Tenants["a"].Capacity)
+	s.Equal(0, stat.PassiveChanks)
+
+	s.pullKeys(12)
+	stat = s.ring.Stat()
+	s.Empty(stat.Tenants)
+	s.Equal(4, stat.PassiveChanks)
+	s.Equal(16, stat.PassiveCapacity)
+
+	for i := range 6 {
+		s.ring.Push("b", i)
+	}
+	stat = s.ring.Stat()
+	s.Equal(8, stat.Tenants["b"].Capacity)
+	s.Equal(2, stat.PassiveChanks)
+}
+
+func (s *FairRingSuite) TestPullWaits() {
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		s.ring.Push("a", 7)
+	}()
+	key, v, err := s.ring.Pull(context.Background())
+	s.NoError(err)
+	s.Equal("a", key)
+	s.Equal(7, v)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, _, err = s.ring.Pull(ctx)
+	s.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func (s *FairRingSuite) TestConcurrent() {
+	const tenants = 4
+	const perTenant = 1000
+
+	wg := &sync.WaitGroup{}
+	for t := range tenants {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perTenant {
+				s.ring.Push(string(rune('a'+t)), i)
+			}
+		}()
+	}
+
+	last := make(map[string]int)
+	pulled := 0
+	for key, v := range s.ring.Elements(context.Background()) {
+		if prev, ok := last[key]; ok {
+			s.Equal(prev+1, v)
+		}
+		last[key] = v
+		pulled++
+		if pulled == tenants*perTenant {
+			break
+		}
+	}
+	wg.Wait()
+	s.Equal(0, s.ring.Size())
+}
+
+func (s *FairRingSuite) TestElementTTL() {
+	clock := newFakeClock()
+	s.ring = NewFairRing[string, int](WithTenantOptions(WithElementTTL(time.Second), WithClock(clock)))
+	s.ring.Push("a", 1)
+	s.ring.Push("b", 2)
+	clock.Advance(time.Second)
+	s.ring.Push("b", 3)
+
+	key, v, err := s.ring.TryPull()
+	s.NoError(err)
+	s.Equal("b", key)
+	s.Equal(3, v)
+	s.Equal(0, s.ring.Size())
+	s.Equal(0, s.ring.Tenants())
+	_, _, err = s.ring.TryPull()
+	s.Equal(io.EOF, err)
+}
+
+func TestFairRingSuite(t *testing.T) {
+	suite.Run(t, new(FairRingSuite))
+}
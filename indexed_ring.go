@@ -0,0 +1,155 @@
+package rubberring
+
+import (
+	"cmp"
+	"errors"
+	"iter"
+	"sort"
+)
+
+var ErrUnorderedKey = errors.New("rubberring: key is less than the key of the last element")
+
+type chankIndex[K cmp.Ordered, V any] struct {
+	chk      *chank[V]
+	firstKey K
+}
+
+// IndexedRing is a RubberRing of elements pushed in non-decreasing key order, it keeps the first key
+// of every chunk, so Search and Range binary search the chunks and then the elements of one chunk.
+// Like RubberRing it is not safe for concurrent use.
+type IndexedRing[K cmp.Ordered, V any] struct {
+	ring *RubberRing[V]
+	key  func(V) K
+	// index holds the chunks of the ring from indexStart in the chain order
+	index      []chankIndex[K, V]
+	indexStart int
+	lastKey    K
+}
+
+// NewIndexedRing accepts the options of RubberRing, WithElementTTL is ignored
+func NewIndexedRing[K cmp.Ordered, V any](key func(V) K, options ...applyConfigFunc) *IndexedRing[K, V] {
+	return &IndexedRing[K, V]{
+		ring: NewRubberRing[V](append(options[:len(options):len(options)], withoutTTL())...),
+		key:  key,
+	}
+}
+
+func (r *IndexedRing[K, V]) Size() int {
+	return r.ring.Size()
+}
+
+func (r *IndexedRing[K, V]) Capacity() int {
+	return r.ring.Capacity()
+}
+
+func (r *IndexedRing[K, V]) Stat() RubberRingStat {
+	return r.ring.Stat()
+}
+
+// Push puts the element, it returns ErrUnorderedKey if the key is less than the key of the last element
+func (r *IndexedRing[K, V]) Push(value V) error {
+	key := r.key(value)
+	if r.ring.offset > 0 && key < r.lastKey {
+		return ErrUnorderedKey
+	}
+	if r.ring.endPosition == 0 {
+		r.index = append(r.index, chankIndex[K, V]{chk: r.ring.endChank, firstKey: key})
+	}
+	r.ring.Push(value)
+	r.lastKey = key
+	return nil
+}
+
+func (r *IndexedRing[K, V]) Pull() (V, error) {
+	v, err := r.ring.Pull()
+	if err != nil {
+		return v, err
+	}
+	// the new start chunk may have no elements yet, then it is indexed by the next Push
+	for r.indexStart < len(r.index) && r.index[r.indexStart].chk != r.ring.startChank {
+		r.index[r.indexStart] = chankIndex[K, V]{}
+		r.indexStart++
+	}
+	if r.indexStart > len(r.index)/2 {
+		r.index = r.index[:copy(r.index, r.index[r.indexStart:])]
+		clear(r.index[len(r.index):cap(r.index)])
+		r.indexStart = 0
+	}
+	return v, nil
+}
+
+func (r *IndexedRing[K, V]) Elements() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for {
+			v, err := r.Pull()
+			if err != nil {
+				return
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Search returns the first element whose key is not less than key, false if there is no such element
+func (r *IndexedRing[K, V]) Search(key K) (V, bool) {
+	for v := range r.from(key) {
+		return v, true
+	}
+	var v V
+	return v, false
+}
+
+// Range iterates over the elements with lo <= key < hi without pulling them
+func (r *IndexedRing[K, V]) Range(lo, hi K) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for v := range r.from(lo) {
+			if r.key(v) >= hi || !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// from iterates over the elements starting from the first one whose key is not less than key
+func (r *IndexedRing[K, V]) from(key K) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		if r.ring.Size() == 0 {
+			return
+		}
+		chanks := r.index[r.indexStart:]
+		// the first chunk whose first key is not less than key, the element may be in the previous one
+		i := sort.Search(len(chanks), func(i int) bool { return chanks[i].firstKey >= key })
+		position := 0
+		if i > 0 {
+			i--
+			low, high := r.bounds(chanks[i].chk)
+			data := chanks[i].chk.data
+			position = low + sort.Search(high-low, func(j int) bool { return r.key(data[low+j]) >= key })
+		} else {
+			position, _ = r.bounds(chanks[0].chk)
+		}
+		for ; i < len(chanks); i++ {
+			_, high := r.bounds(chanks[i].chk)
+			for ; position < high; position++ {
+				if !yield(chanks[i].chk.data[position]) {
+					return
+				}
+			}
+			position = 0
+		}
+	}
+}
+
+// bounds returns the positions of the elements of the ring in the chunk
+func (r *IndexedRing[K, V]) bounds(chk *chank[V]) (low, high int) {
+	high = len(chk.data)
+	if chk == r.ring.endChank {
+		high = r.ring.endPosition
+	}
+	if chk == r.ring.startChank {
+		low = r.ring.startPosition
+	}
+	return low, high
+}
@@ -0,0 +1,119 @@
+package rubberring
+
+import (
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type IndexedRingSuite struct {
+	suite.Suite
+	ring *IndexedRing[int, int]
+}
+
+func (s *IndexedRingSuite) SetupTest() {
+	s.ring = NewIndexedRing[int, int](
+		func(v int) int { return v / 10 },
+		WithStartChankSize(4),
+		WithStartChankCount(1),
+		WithGrowStrategy(func(int) (int, int) { return 4, 1 }),
+	)
+}
+
+func (s *IndexedRingSuite) TearDownTest() {
+	s.ring = nil
+}
+
+func (s *IndexedRingSuite) push(values ...int) {
+	for _, v := range values {
+		s.Require().NoError(s.ring.Push(v))
+	}
+}
+
+func (s *IndexedRingSuite) TestUnorderedKey() {
+	s.push(10, 11, 20)
+	s.ErrorIs(s.ring.Push(15), ErrUnorderedKey)
+	s.NoError(s.ring.Push(21))
+	s.Equal(4, s.ring.Size())
+}
+
+func (s *IndexedRingSuite) TestSearch() {
+	// keys: 0 1 1 1 | 2 4 4 4 | 4 5 7 9
+	s.push(0, 10, 11, 12, 20, 40, 41, 42, 43, 50, 70, 90)
+
+	tests := []struct {
+		key      int
+		expected int
+		found    bool
+	}{
+		{key: -1, expected: 0, found: true},
+		{key: 1, expected: 10, found: true},
+		{key: 3, expected: 40, found: true},
+		{key: 4, expected: 40, found: true},
+		{key: 6, expected: 70, found: true},
+		{key: 9, expected: 90, found: true},
+		{key: 10, found: false},
+	}
+	for _, tt := range tests {
+		v, ok := s.ring.Search(tt.key)
+		s.Equal(tt.found, ok, "key %d", tt.key)
+		s.Equal(tt.expected, v, "key %d", tt.key)
+	}
+}
+
+func (s *IndexedRingSuite) TestRange() {
+	s.push(0, 10, 11, 12, 20, 40, 41, 42, 43, 50, 70, 90)
+	s.Equal([]int{10, 11, 12, 20}, slices.Collect(s.ring.Range(1, 3)))
+	s.Equal([]int{40, 41, 42, 43, 50}, slices.Collect(s.ring.Range(4, 7)))
+	s.Empty(slices.Collect(s.ring.Range(95, 100)))
+	s.Equal(12, s.ring.Size())
+}
+
+func (s *IndexedRingSuite) TestPullKeepsIndex() {
+	for i := range 40 {
+		s.push(i * 10)
+	}
+	for range 30 {
+		_, err := s.ring.Pull()
+		s.NoError(err)
+	}
+	s.LessOrEqual(len(s.ring.index), 6)
+
+	v, ok := s.ring.Search(0)
+	s.True(ok)
+	s.Equal(300, v)
+	s.Equal([]int{330, 340, 350}, slices.Collect(s.ring.Range(33, 36)))
+
+	for range 10 {
+		s.ring.Pull()
+	}
+	_, err := s.ring.Pull()
+	s.Equal(io.EOF, err)
+	_, ok = s.ring.Search(0)
+	s.False(ok)
+
+	s.push(500, 510)
+	s.Equal([]int{500, 510}, slices.Collect(s.ring.Range(0, 100)))
+}
+
+func (s *IndexedRingSuite) TestElementTTLIgnored() {
+	clock := newFakeClock()
+	s.ring = NewIndexedRing[int, int](func(v int) int { return v }, WithElementTTL(time.Second), WithClock(clock))
+	s.push(1, 2, 3)
+	clock.Advance(time.Hour)
+
+	v, ok := s.ring.Search(2)
+	s.True(ok)
+	s.Equal(2, v)
+	v, err := s.ring.Pull()
+	s.NoError(err)
+	s.Equal(1, v)
+	s.Equal(2, s.ring.Size())
+}
+
+func TestIndexedRingSuite(t *testing.T) {
+	suite.Run(t, new(IndexedRingSuite))
+}
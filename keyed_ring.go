@@ -0,0 +1,239 @@
+package rubberring
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+var ErrConsumerLeft = errors.New("rubberring: consumer left the keyed ring")
+
+// KeyedRing routes elements by key hash to SyncRubberRing partitions, every partition
+// is bound to one consumer, so elements with the same key are processed in order
+// while different keys are processed in parallel.
+type KeyedRing[K comparable, V any] struct {
+	hash       func(K) uint64
+	partitions []*keyedPartition[K, V]
+
+	mu        sync.Mutex
+	consumers []*KeyedConsumer[K, V]
+}
+
+type keyedPartition[K comparable, V any] struct {
+	ring *SyncRubberRing[V]
+	// owner is changed under mu and loaded by Push without it
+	owner atomic.Pointer[KeyedConsumer[K, V]]
+	// busy is the consumer that processes an element of the partition right now,
+	// the partition is not handed to a new owner until it is released
+	busy *KeyedConsumer[K, V]
+}
+
+// KeyedConsumer is used by one goroutine, it is woken only by pushes to its own partitions
+type KeyedConsumer[K comparable, V any] struct {
+	ring       *KeyedRing[K, V]
+	wake       *notifier
+	partitions []int
+	next       int
+	held       int
+	left       bool
+}
+
+func NewKeyedRing[K comparable, V any](
+	partitions int,
+	hash func(K) uint64,
+	options ...applyConfigFunc,
+) *KeyedRing[K, V] {
+	if partitions < 1 {
+		partitions = 1
+	}
+	r := &KeyedRing[K, V]{
+		hash:       hash,
+		partitions: make([]*keyedPartition[K, V], partitions),
+	}
+	for i := range r.partitions {
+		r.partitions[i] = &keyedPartition[K, V]{ring: NewSyncRubberRing[V](options...)}
+	}
+	return r
+}
+
+func (r *KeyedRing[K, V]) Partitions() int {
+	return len(r.partitions)
+}
+
+func (r *KeyedRing[K, V]) Partition(key K) int {
+	return int(r.hash(key) % uint64(len(r.partitions)))
+}
+
+func (r *KeyedRing[K, V]) Size() int {
+	size := 0
+	for _, p := range r.partitions {
+		size += p.ring.Size()
+	}
+	return size
+}
+
+func (r *KeyedRing[K, V]) Stat() []RubberRingStat {
+	stat := make([]RubberRingStat, len(r.partitions))
+	for i, p := range r.partitions {
+		stat[i] = p.ring.Stat()
+	}
+	return stat
+}
+
+// Push wakes only the consumer that owns the partition of the key
+func (r *KeyedRing[K, V]) Push(key K, value V) {
+	p := r.partitions[r.Partition(key)]
+	p.ring.Push(value)
+	// the owner is loaded after the push, so a consumer that gets the partition later
+	// is woken by the rebalance and sees the element
+	if owner := p.owner.Load(); owner != nil {
+		owner.wake.notify()
+	}
+}
+
+// Join adds a consumer and rebalances the partitions between consumers
+func (r *KeyedRing[K, V]) Join() *KeyedConsumer[K, V] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := &KeyedConsumer[K, V]{ring: r, wake: newNotifier(), held: -1}
+	r.consumers = append(r.consumers, c)
+	r.rebalanceLocked()
+	return c
+}
+
+func (r *KeyedRing[K, V]) rebalanceLocked() {
+	for _, c := range r.consumers {
+		c.partitions = c.partitions[:0]
+		c.next = 0
+	}
+	for i, p := range r.partitions {
+		var owner *KeyedConsumer[K, V]
+		if len(r.consumers) > 0 {
+			owner = r.consumers[i%len(r.consumers)]
+			owner.partitions = append(owner.partitions, i)
+		}
+		p.owner.Store(owner)
+	}
+	for _, c := range r.consumers {
+		c.wake.notify()
+	}
+}
+
+// Partitions returns the partitions currently bound to the consumer
+func (c *KeyedConsumer[K, V]) Partitions() []int {
+	c.ring.mu.Lock()
+	defer c.ring.mu.Unlock()
+	return append([]int(nil), c.partitions...)
+}
+
+// Pull releases the partition of the previously pulled element and waits for an element
+// from the partitions bound to the consumer
+func (c *KeyedConsumer[K, V]) Pull(ctx context.Context) (V, error) {
+	r := c.ring
+	r.mu.Lock()
+	c.releaseLocked()
+	r.mu.Unlock()
+	var (
+		v    V
+		ok   bool
+		left bool
+	)
+	ready := func() bool {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		left = c.left
+		if !left {
+			v, ok = c.tryPullLocked()
+		}
+		return left || ok
+	}
+	for {
+		if err := c.wake.wait(ctx, ready); err != nil {
+			return v, err
+		}
+		if left {
+			return v, ErrConsumerLeft
+		}
+		if ok {
+			return v, nil
+		}
+	}
+}
+
+func (c *KeyedConsumer[K, V]) tryPullLocked() (V, bool) {
+	for range c.partitions {
+		i := c.partitions[c.next%len(c.partitions)]
+		c.next++
+		p := c.ring.partitions[i]
+		if p.busy != nil {
+			continue
+		}
+		v, err := p.ring.TryPull()
+		if err != nil {
+			continue
+		}
+		p.busy = c
+		c.held = i
+		return v, true
+	}
+	var v V
+	return v, false
+}
+
+// Done releases the partition of the previously pulled element without pulling the next one
+func (c *KeyedConsumer[K, V]) Done() {
+	c.ring.mu.Lock()
+	defer c.ring.mu.Unlock()
+	c.releaseLocked()
+}
+
+func (c *KeyedConsumer[K, V]) releaseLocked() {
+	if c.held < 0 {
+		return
+	}
+	p := c.ring.partitions[c.held]
+	c.held = -1
+	if p.busy == c {
+		p.busy = nil
+		if owner := p.owner.Load(); owner != nil && owner != c {
+			owner.wake.notify()
+		}
+	}
+}
+
+// Leave releases the held partition and rebalances the partitions between the other consumers
+func (c *KeyedConsumer[K, V]) Leave() {
+	r := c.ring
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c.left {
+		return
+	}
+	c.left = true
+	c.releaseLocked()
+	for i, consumer := range r.consumers {
+		if consumer == c {
+			r.consumers = append(r.consumers[:i], r.consumers[i+1:]...)
+			break
+		}
+	}
+	c.partitions = nil
+	r.rebalanceLocked()
+	c.wake.notify()
+}
+
+func (c *KeyedConsumer[K, V]) Elements(ctx context.Context) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for {
+			v, err := c.Pull(ctx)
+			if err != nil {
+				return
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package rubberring
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/goleak"
+)
+
+type keyedEvent struct {
+	key int
+	seq int
+}
+
+type KeyedRingSuite struct {
+	suite.Suite
+	ring *KeyedRing[int, keyedEvent]
+}
+
+func (s *KeyedRingSuite) SetupTest() {
+	s.ring = NewKeyedRing[int, keyedEvent](
+		4,
+		func(key int) uint64 { return uint64(key) },
+		WithStartChankSize(2),
+		WithStartChankCount(1),
+	)
+}
+
+func (s *KeyedRingSuite) TearDownTest() {
+	s.NoError(goleak.Find())
+	s.ring = nil
+}
+
+func (s *KeyedRingSuite) TestRouting() {
+	for i := range 8 {
+		s.ring.Push(i, keyedEvent{key: i})
+	}
+	s.Equal(8, s.ring.Size())
+	stat := s.ring.Stat()
+	s.Len(stat, 4)
+	for _, partition := range stat {
+		s.Equal(2, partition.Size)
+	}
+	s.Equal(1, s.ring.Partition(5))
+}
+
+func (s *KeyedRingSuite) TestRebalance() {
+	first := s.ring.Join()
+	s.Equal([]int{0, 1, 2, 3}, first.Partitions())
+
+	second := s.ring.Join()
+	s.Equal([]int{0, 2}, first.Partitions())
+	s.Equal([]int{1, 3}, second.Partitions())
+
+	first.Leave()
+	s.Equal([]int{0, 1, 2, 3}, second.Partitions())
+
+	_, err := first.Pull(context.Background())
+	s.ErrorIs(err, ErrConsumerLeft)
+	second.Leave()
+}
+
+func (s *KeyedRingSuite) TestHeldPartitionIsNotHandedOver() {
+	first := s.ring.Join()
+	s.ring.Push(1, keyedEvent{key: 1, seq: 0})
+	s.ring.Push(1, keyedEvent{key: 1, seq: 1})
+
+	v, err := first.Pull(context.Background())
+	s.NoError(err)
+	s.Equal(0, v.seq)
+
+	second := s.ring.Join()
+	s.Equal([]int{1, 3}, second.Partitions())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = second.Pull(ctx)
+	s.ErrorIs(err, context.DeadlineExceeded)
+
+	got := make(chan keyedEvent)
+	go func() {
+		v, err := second.Pull(context.Background())
+		s.NoError(err)
+		got <- v
+	}()
+	time.Sleep(10 * time.Millisecond)
+	first.Done()
+	s.Equal(1, (<-got).seq)
+
+	first.Leave()
+	second.Leave()
+}
+
+func (s *KeyedRingSuite) TestPullWaits() {
+	consumer := s.ring.Join()
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		s.ring.Push(3, keyedEvent{key: 3})
+	}()
+	v, err := consumer.Pull(context.Background())
+	s.NoError(err)
+	s.Equal(3, v.key)
+	consumer.Leave()
+}
+
+func (s *KeyedRingSuite) TestPerKeyOrder() {
+	const keys = 16
+	const perKey = 300
+	const consumers = 3
+
+	wg := &sync.WaitGroup{}
+	mu := &sync.Mutex{}
+	last := make(map[int]int)
+	total := 0
+	done := make(chan struct{})
+	joined := make([]*KeyedConsumer[int, keyedEvent], 0, consumers)
+	for range consumers {
+		consumer := s.ring.Join()
+		joined = append(joined, consumer)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer consumer.Leave()
+			for {
+				v, err := consumer.Pull(context.Background())
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				if seq, ok := last[v.key]; ok {
+					s.Equal(seq+1, v.seq)
+				} else {
+					s.Equal(0, v.seq)
+				}
+				last[v.key] = v.seq
+				total++
+				if total == keys*perKey {
+					close(done)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	leaving := s.ring.Join()
+	for seq := range perKey {
+		for key := range keys {
+			s.ring.Push(key, keyedEvent{key: key, seq: seq})
+		}
+		if seq == perKey/2 {
+			leaving.Leave()
+		}
+	}
+	<-done
+
+	for _, consumer := range joined {
+		consumer.Leave()
+	}
+	wg.Wait()
+	s.Equal(0, s.ring.Size())
+}
+
+func (s *KeyedRingSuite) TestPushWakesOnlyOwner() {
+	first := s.ring.Join()
+	second := s.ring.Join()
+	s.Equal([]int{1, 3}, second.Partitions())
+
+	// second waits, a push to a partition of first does not wake it
+	second.wake.waiters.Add(1)
+	s.ring.Push(2, keyedEvent{key: 2})
+	s.Len(second.wake.ch, 0)
+	s.ring.Push(3, keyedEvent{key: 3})
+	s.Len(second.wake.ch, 1)
+	second.wake.waiters.Add(-1)
+
+	v, err := second.Pull(context.Background())
+	s.NoError(err)
+	s.Equal(3, v.key)
+	v, err = first.Pull(context.Background())
+	s.NoError(err)
+	s.Equal(2, v.key)
+
+	first.Leave()
+	second.Leave()
+}
+
+func TestKeyedRingSuite(t *testing.T) {
+	suite.Run(t, new(KeyedRingSuite))
+}
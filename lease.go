@@ -0,0 +1,294 @@
+package rubberring
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"time"
+)
+
+var ErrLeaseExpired = errors.New("rubberring: lease expired or already settled")
+
+// Delivery is an element leased from SyncRubberRing, it must be settled with Ack or Nack
+// before the visibility timeout, otherwise it is redelivered
+type Delivery[V any] struct {
+	Value V
+	// Attempt is 1 for the first delivery and grows with every redelivery
+	Attempt int
+
+	id   uint64
+	ring *SyncRubberRing[V]
+}
+
+// Ack removes the element for good
+func (d Delivery[V]) Ack() error {
+	_, err := d.ring.settle(d.id)
+	return err
+}
+
+// Nack returns the element to the ring for redelivery
+func (d Delivery[V]) Nack() error {
+	entry, err := d.ring.settle(d.id)
+	if err != nil {
+		return err
+	}
+	d.ring.headMu.Lock()
+	d.ring.requeueLocked(entry)
+	d.ring.headMu.Unlock()
+	d.ring.flushDeadLetters()
+	d.ring.signal()
+	return nil
+}
+
+type leaseEntry[V any] struct {
+	value V
+	// offset is kept for PullWithOffset of the redelivered element
+	offset   uint64
+	attempts int
+	deadline time.Time
+	// after is the number of pulled elements after which the redelivered element is returned
+	after int64
+}
+
+type leaseDeadline struct {
+	id       uint64
+	deadline time.Time
+}
+
+type leaseState[V any] struct {
+	nextID   uint64
+	inflight map[uint64]leaseEntry[V]
+	// deadlines are in the lease order, so they grow, as the visibility timeout is the same for all leases
+	deadlines *RubberRing[leaseDeadline]
+	// redelivery holds nacked and timed out elements, they are counted in the size of the ring
+	// and returned by every kind of pull
+	redelivery *RubberRing[leaseEntry[V]]
+	deadLetter *SyncRubberRing[V]
+	// dead are pushed to deadLetter after headMu is released, as it may be the same ring
+	dead []V
+}
+
+// SetDeadLetter sets the ring for elements that exceeded WithMaxRedeliveries,
+// without it such elements are dropped
+func (r *SyncRubberRing[V]) SetDeadLetter(deadLetter *SyncRubberRing[V]) {
+	r.headMu.Lock()
+	defer r.headMu.Unlock()
+	r.leasesLocked().deadLetter = deadLetter
+}
+
+// InFlight returns the number of leased elements that are not settled yet
+func (r *SyncRubberRing[V]) InFlight() int {
+	r.headMu.Lock()
+	defer r.headMu.Unlock()
+	if r.leases == nil {
+		return 0
+	}
+	return len(r.leases.inflight)
+}
+
+// Lease waits for an element like Pull, but keeps it in flight until it is acknowledged.
+// Elements that are nacked or not settled within the visibility timeout are returned to the ring.
+func (r *SyncRubberRing[V]) Lease(ctx context.Context) (Delivery[V], error) {
+	r.headMu.Lock()
+	r.leasesLocked()
+	r.headMu.Unlock()
+	return waitLeased(ctx, r, r.leaseLocked)
+}
+
+// waitLeased calls take with headMu held until it succeeds, it waits for new elements
+// and for the deadline of the earliest lease
+func waitLeased[V, T any](ctx context.Context, r *SyncRubberRing[V], take func(now time.Time) (T, bool)) (T, error) {
+	ch := make(chan struct{}, 1)
+	r.watch(ch)
+	defer r.unwatch(ch)
+
+	for {
+		r.headMu.Lock()
+		// the watcher is registered before the check, so an element pushed after it is notified
+		now := r.ring.config.clock.Now()
+		v, ok := take(now)
+		var timer <-chan time.Time
+		if !ok {
+			if deadline, ok := r.leaseDeadlineLocked(); ok {
+				timer = r.ring.config.clock.After(deadline.Sub(now))
+			}
+		}
+		dead := len(r.leases.dead) > 0
+		r.headMu.Unlock()
+		if dead {
+			r.flushDeadLetters()
+		}
+		if ok {
+			return v, nil
+		}
+
+		select {
+		case <-ch:
+		case <-timer:
+		case <-ctx.Done():
+			return v, ctx.Err()
+		}
+	}
+}
+
+// leaseDeadlineLocked returns the deadline of the earliest lease in flight,
+// the deadlines of settled leases are dropped on the way
+func (r *SyncRubberRing[V]) leaseDeadlineLocked() (time.Time, bool) {
+	if r.leases == nil {
+		return time.Time{}, false
+	}
+	for {
+		next, err := r.leases.deadlines.Peek()
+		if err != nil {
+			return time.Time{}, false
+		}
+		if _, ok := r.leases.inflight[next.id]; ok {
+			return next.deadline, true
+		}
+		r.leases.deadlines.discard()
+	}
+}
+
+// leaseTimeout returns the time left until the earliest lease deadline, false if nothing is leased
+func (r *SyncRubberRing[V]) leaseTimeout() (time.Duration, bool) {
+	if !r.leasing.Load() {
+		return 0, false
+	}
+	r.headMu.Lock()
+	defer r.headMu.Unlock()
+	deadline, ok := r.leaseDeadlineLocked()
+	if !ok {
+		return 0, false
+	}
+	return deadline.Sub(r.ring.config.clock.Now()), true
+}
+
+func (r *SyncRubberRing[V]) leasesLocked() *leaseState[V] {
+	if r.leases == nil {
+		r.leasing.Store(true)
+		r.leases = &leaseState[V]{
+			inflight:   make(map[uint64]leaseEntry[V]),
+			deadlines:  NewRubberRing[leaseDeadline](WithStartChankSize(64), WithStartChankCount(1)),
+			redelivery: NewRubberRing[leaseEntry[V]](WithStartChankSize(64), WithStartChankCount(1)),
+		}
+	}
+	return r.leases
+}
+
+func (r *SyncRubberRing[V]) leaseLocked(now time.Time) (Delivery[V], bool) {
+	leases := r.leasesLocked()
+	entry, ok := r.pullEntryLocked()
+	if !ok {
+		return Delivery[V]{}, false
+	}
+
+	entry.attempts++
+	entry.deadline = now.Add(r.ring.config.visibilityTimeout)
+	leases.nextID++
+	leases.inflight[leases.nextID] = entry
+	leases.deadlines.Push(leaseDeadline{id: leases.nextID, deadline: entry.deadline})
+	if leases.deadlines.Size() == 1 && r.watching.Load() > 0 {
+		// a waiter of several rings that saw no deadline has to start a timer for this one
+		r.notifyWatchers()
+	}
+	return Delivery[V]{
+		Value:   entry.value,
+		Attempt: entry.attempts,
+		id:      leases.nextID,
+		ring:    r,
+	}, true
+}
+
+func (r *SyncRubberRing[V]) expireLocked(now time.Time) {
+	leases := r.leases
+	for {
+		next, err := leases.deadlines.Peek()
+		if err != nil {
+			return
+		}
+		entry, ok := leases.inflight[next.id]
+		if ok && next.deadline.After(now) {
+			return
+		}
+		_, _ = leases.deadlines.Pull()
+		if !ok {
+			continue
+		}
+		delete(leases.inflight, next.id)
+		r.requeueLocked(entry)
+	}
+}
+
+// requeueLocked puts the element to the redelivery ring or to the dead letter queue
+func (r *SyncRubberRing[V]) requeueLocked(entry leaseEntry[V]) {
+	if limit := r.ring.config.maxRedeliveries; limit > 0 && entry.attempts > limit {
+		if r.leases.deadLetter != nil {
+			r.leases.dead = append(r.leases.dead, entry.value)
+		}
+		return
+	}
+	entry.after = r.pulled + r.size.Load()
+	r.leases.redelivery.Push(entry)
+	r.requeued.Add(1)
+}
+
+// redeliverLocked takes the redelivered element if it is due: at once with WithNackToFront,
+// otherwise after the elements that were in the ring when it was requeued
+func (r *SyncRubberRing[V]) redeliverLocked() (leaseEntry[V], bool) {
+	leases := r.leases
+	if len(leases.inflight) > 0 {
+		r.expireLocked(r.ring.config.clock.Now())
+	}
+	next, err := leases.redelivery.Peek()
+	if err != nil || !(r.ring.config.nackToFront || r.pulled >= next.after || r.size.Load() == 0) {
+		return leaseEntry[V]{}, false
+	}
+	leases.redelivery.discard()
+	r.requeued.Add(-1)
+	return next, true
+}
+
+// appendLeasedLocked appends the elements in flight in the lease order and the redelivered elements
+func (r *SyncRubberRing[V]) appendLeasedLocked(dst []V, inflight bool) []V {
+	if r.leases == nil {
+		return dst
+	}
+	if inflight {
+		for _, id := range slices.Sorted(maps.Keys(r.leases.inflight)) {
+			dst = append(dst, r.leases.inflight[id].value)
+		}
+	}
+	for entry := range r.leases.redelivery.all() {
+		dst = append(dst, entry.value)
+	}
+	return dst
+}
+
+func (r *SyncRubberRing[V]) flushDeadLetters() {
+	r.headMu.Lock()
+	if r.leases == nil || len(r.leases.dead) == 0 {
+		r.headMu.Unlock()
+		return
+	}
+	dead, deadLetter := r.leases.dead, r.leases.deadLetter
+	r.leases.dead = nil
+	r.headMu.Unlock()
+	for _, v := range dead {
+		deadLetter.Push(v)
+	}
+}
+
+func (r *SyncRubberRing[V]) settle(id uint64) (leaseEntry[V], error) {
+	r.headMu.Lock()
+	defer r.headMu.Unlock()
+	if r.leases == nil {
+		return leaseEntry[V]{}, ErrLeaseExpired
+	}
+	entry, ok := r.leases.inflight[id]
+	if !ok {
+		return entry, ErrLeaseExpired
+	}
+	delete(r.leases.inflight, id)
+	return entry, nil
+}
@@ -0,0 +1,258 @@
+package rubberring
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/goleak"
+)
+
+type LeaseSuite struct {
+	suite.Suite
+	clock *fakeClock
+	ring  *SyncRubberRing[int]
+}
+
+func (s *LeaseSuite) SetupTest() {
+	s.clock = newFakeClock()
+	s.ring = NewSyncRubberRing[int](WithVisibilityTimeout(time.Second), WithClock(s.clock))
+}
+
+func (s *LeaseSuite) TearDownTest() {
+	s.NoError(goleak.Find())
+	s.ring = nil
+}
+
+func (s *LeaseSuite) lease() Delivery[int] {
+	d, err := s.ring.Lease(context.Background())
+	s.Require().NoError(err)
+	return d
+}
+
+func (s *LeaseSuite) TestAck() {
+	s.ring.Push(1)
+	d := s.lease()
+	s.Equal(1, d.Value)
+	s.Equal(1, d.Attempt)
+	s.Equal(0, s.ring.Size())
+	s.Equal(1, s.ring.InFlight())
+
+	s.NoError(d.Ack())
+	s.Equal(0, s.ring.InFlight())
+	s.ErrorIs(d.Ack(), ErrLeaseExpired)
+	s.ErrorIs(d.Nack(), ErrLeaseExpired)
+}
+
+func (s *LeaseSuite) TestNackToTail() {
+	s.ring.Push(1)
+	s.ring.Push(2)
+	d := s.lease()
+	s.ring.Push(3)
+	s.NoError(d.Nack())
+
+	d = s.lease()
+	s.Equal(2, d.Value)
+	s.NoError(d.Ack())
+	d = s.lease()
+	s.Equal(3, d.Value)
+	s.NoError(d.Ack())
+	d = s.lease()
+	s.Equal(1, d.Value)
+	s.Equal(2, d.Attempt)
+	s.NoError(d.Ack())
+}
+
+func (s *LeaseSuite) TestNackToFront() {
+	s.ring = NewSyncRubberRing[int](WithNackToFront())
+	s.ring.Push(1)
+	s.ring.Push(2)
+	d := s.lease()
+	s.NoError(d.Nack())
+
+	d = s.lease()
+	s.Equal(1, d.Value)
+	s.Equal(2, d.Attempt)
+}
+
+func (s *LeaseSuite) TestExpiry() {
+	s.ring.Push(1)
+	d := s.lease()
+
+	got := make(chan Delivery[int])
+	go func() {
+		got <- s.lease()
+	}()
+	s.Eventually(func() bool { return s.clock.Timers() == 1 }, time.Second, time.Millisecond)
+	s.clock.Advance(time.Second)
+
+	redelivered := <-got
+	s.Equal(1, redelivered.Value)
+	s.Equal(2, redelivered.Attempt)
+	s.ErrorIs(d.Ack(), ErrLeaseExpired)
+	s.NoError(redelivered.Ack())
+}
+
+func (s *LeaseSuite) TestWaitsForNack() {
+	s.ring.Push(1)
+	d := s.lease()
+
+	got := make(chan Delivery[int])
+	go func() {
+		got <- s.lease()
+	}()
+	s.Eventually(func() bool { return s.clock.Timers() == 1 }, time.Second, time.Millisecond)
+	s.NoError(d.Nack())
+	s.Equal(1, (<-got).Value)
+}
+
+func (s *LeaseSuite) TestDeadLetter() {
+	s.ring = NewSyncRubberRing[int](WithMaxRedeliveries(2))
+	deadLetter := NewSyncRubberRing[int]()
+	s.ring.SetDeadLetter(deadLetter)
+
+	s.ring.Push(1)
+	for attempt := 1; attempt <= 3; attempt++ {
+		d := s.lease()
+		s.Equal(attempt, d.Attempt)
+		s.NoError(d.Nack())
+	}
+	s.Equal(1, deadLetter.Size())
+	s.Equal(0, s.ring.InFlight())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := s.ring.Lease(ctx)
+	s.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func (s *LeaseSuite) TestConcurrent() {
+	s.ring = NewSyncRubberRing[int](WithNackToFront())
+	const count = 2000
+	go func() {
+		for i := range count {
+			s.ring.Push(i)
+		}
+	}()
+
+	mu := &sync.Mutex{}
+	acked := make(map[int]int)
+	wg := &sync.WaitGroup{}
+	for w := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				mu.Lock()
+				done := len(acked) == count
+				mu.Unlock()
+				if done {
+					return
+				}
+				ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+				d, err := s.ring.Lease(ctx)
+				cancel()
+				if err != nil {
+					continue
+				}
+				if (d.Value+w)%7 == 0 && d.Attempt == 1 {
+					s.NoError(d.Nack())
+					continue
+				}
+				s.NoError(d.Ack())
+				mu.Lock()
+				acked[d.Value]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	s.Len(acked, count)
+	for _, times := range acked {
+		s.Equal(1, times)
+	}
+}
+
+func (s *LeaseSuite) TestNackReturnsToRing() {
+	s.ring.Push(1)
+	d := s.lease()
+	s.Equal(0, s.ring.Size())
+	s.NoError(d.Nack())
+	s.Equal(1, s.ring.Size())
+	s.Equal([]int{1}, s.ring.ToSlice())
+
+	v, err := s.ring.TryPull()
+	s.NoError(err)
+	s.Equal(1, v)
+	s.Equal(0, s.ring.Size())
+
+	s.ring.Push(2)
+	d = s.lease()
+	s.NoError(d.Nack())
+	i, v, err := PullAny(context.Background(), NewSyncRubberRing[int](), s.ring)
+	s.NoError(err)
+	s.Equal(1, i)
+	s.Equal(2, v)
+}
+
+func (s *LeaseSuite) TestPullWaitsForExpiry() {
+	s.ring.Push(1)
+	s.lease()
+
+	got := make(chan int)
+	go func() {
+		v, err := s.ring.Pull(context.Background())
+		s.NoError(err)
+		got <- v
+	}()
+	s.Eventually(func() bool { return s.clock.Timers() == 1 }, time.Second, time.Millisecond)
+	s.clock.Advance(time.Second)
+	s.Equal(1, <-got)
+	s.Equal(0, s.ring.InFlight())
+}
+
+func (s *LeaseSuite) TestDumpKeepsLeased() {
+	for i := range 4 {
+		s.ring.Push(i)
+	}
+	s.lease()
+	s.NoError(s.lease().Nack())
+	s.Equal(3, s.ring.Size())
+	s.Equal(1, s.ring.InFlight())
+	s.Equal([]int{0, 1, 2, 3}, s.ring.Snapshot().ToSlice())
+
+	buf := &bytes.Buffer{}
+	_, err := s.ring.WriteTo(buf, JSONCodec[int]())
+	s.NoError(err)
+	restored, err := LoadSyncRubberRing(buf, JSONCodec[int]())
+	s.NoError(err)
+	s.Equal([]int{0, 1, 2, 3}, restored.ToSlice())
+}
+
+func (s *LeaseSuite) TestPullAnyWaitsForExpiry() {
+	other := NewSyncRubberRing[int]()
+	s.ring.Push(1)
+	s.ring.Push(2)
+	s.NoError(s.lease().Ack())
+	s.lease()
+
+	got := make(chan int)
+	go func() {
+		i, v, err := PullAny(context.Background(), other, s.ring)
+		s.NoError(err)
+		s.Equal(1, i)
+		got <- v
+	}()
+	// the deadline of the acked lease is skipped, the timer is set for the one in flight
+	s.Eventually(func() bool { return s.clock.Timers() == 1 }, time.Second, time.Millisecond)
+	s.clock.Advance(time.Second)
+	s.Equal(2, <-got)
+	s.Equal(0, s.ring.InFlight())
+}
+
+func TestLeaseSuite(t *testing.T) {
+	suite.Run(t, new(LeaseSuite))
+}
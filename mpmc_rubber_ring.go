@@ -0,0 +1,247 @@
+package rubberring
+
+import (
+	"context"
+	"io"
+	"iter"
+	"runtime"
+	"sync/atomic"
+)
+
+type mpmcSlot[V any] struct {
+	// seq is position+1 once the value of the position is written
+	seq   atomic.Uint64
+	value V
+}
+
+type mpmcChank[V any] struct {
+	slots     []mpmcSlot[V]
+	base      uint64
+	nextChank atomic.Pointer[mpmcChank[V]]
+}
+
+func (c *mpmcChank[V]) end() uint64 {
+	return c.base + uint64(len(c.slots))
+}
+
+type retiredChank[V any] struct {
+	chank *mpmcChank[V]
+	epoch uint64
+}
+
+// MPMCRubberRing is a lock-free ring for any number of producers and consumers.
+// Every element gets an absolute position, chunks cover consecutive ranges of positions
+// and are linked by CAS, drained chunks are reused only when no goroutine can still reference them.
+type MPMCRubberRing[V any] struct {
+	head       atomic.Pointer[mpmcChank[V]]
+	_          cacheLinePad
+	tail       atomic.Pointer[mpmcChank[V]]
+	_          cacheLinePad
+	enqueue    atomic.Uint64
+	_          cacheLinePad
+	dequeue    atomic.Uint64
+	_          cacheLinePad
+	capacity   atomic.Int64
+	freeChanks chan retiredChank[V]
+	epoch      *epochGuard
+	notifier   *notifier
+	config     config
+}
+
+func NewMPMCRubberRing[V any](options ...applyConfigFunc) *MPMCRubberRing[V] {
+	config := defaultConfig
+	for _, option := range options {
+		option(&config)
+	}
+
+	rr := &MPMCRubberRing[V]{
+		config:     config,
+		freeChanks: make(chan retiredChank[V], config.pasiveChankBufferSize),
+		epoch:      newEpochGuard(),
+		notifier:   newNotifier(),
+	}
+	chanks := rr.createChankChain(0, config.startChankSize, config.startChankCount)
+	rr.head.Store(chanks)
+	rr.tail.Store(chanks)
+	return rr
+}
+
+func (r *MPMCRubberRing[V]) Size() int {
+	dequeue := r.dequeue.Load()
+	return int(r.enqueue.Load() - dequeue)
+}
+
+func (r *MPMCRubberRing[V]) Capacity() int {
+	return int(r.capacity.Load())
+}
+
+func (r *MPMCRubberRing[V]) Push(el V) {
+	epoch := r.epoch.pin()
+	position := r.enqueue.Add(1) - 1
+	chk := r.tail.Load()
+	if position < chk.base {
+		// the tail was moved past our chunk by a later producer
+		chk = r.head.Load()
+	}
+	chk = r.find(chk, position)
+	// the chunk can not be drained before the slot is published, so the tail never points to a reused chunk
+	r.advance(&r.tail, chk.base, chk)
+	slot := &chk.slots[position-chk.base]
+	slot.value = el
+	slot.seq.Store(position + 1)
+	r.epoch.unpin(epoch)
+	r.notifier.notify()
+}
+
+// TryPull returns io.EOF if the ring is empty
+func (r *MPMCRubberRing[V]) TryPull() (V, error) {
+	var el V
+	epoch := r.epoch.pin()
+	for {
+		position := r.dequeue.Load()
+		chk := r.head.Load()
+		if position < chk.base {
+			continue
+		}
+		chk = r.find(chk, position)
+		slot := &chk.slots[position-chk.base]
+		if slot.seq.Load() != position+1 {
+			if position >= r.enqueue.Load() {
+				r.epoch.unpin(epoch)
+				return el, io.EOF
+			}
+			// the position is taken by a producer that has not written the value yet
+			runtime.Gosched()
+			continue
+		}
+		if !r.dequeue.CompareAndSwap(position, position+1) {
+			continue
+		}
+		el = slot.value
+		var zero V
+		slot.value = zero
+		if position+1 == chk.end() {
+			r.retire(chk)
+		}
+		r.epoch.unpin(epoch)
+		return el, nil
+	}
+}
+
+func (r *MPMCRubberRing[V]) Pull(ctx context.Context) (V, error) {
+	for {
+		v, err := r.TryPull()
+		if err == nil {
+			return v, nil
+		}
+		if err := r.notifier.wait(ctx, func() bool { return r.Size() > 0 }); err != nil {
+			return v, err
+		}
+		v, err = r.TryPull()
+		if err == nil {
+			// several consumers may wait, but one notification wakes only one of them
+			if r.Size() > 0 {
+				r.notifier.notify()
+			}
+			return v, nil
+		}
+	}
+}
+
+func (r *MPMCRubberRing[V]) Elements(ctx context.Context) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for {
+			v, err := r.Pull(ctx)
+			if err != nil {
+				return
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// find walks from chk to the chunk holding position, extending the chain if needed
+func (r *MPMCRubberRing[V]) find(chk *mpmcChank[V], position uint64) *mpmcChank[V] {
+	for position >= chk.end() {
+		chk = r.next(chk)
+	}
+	return chk
+}
+
+func (r *MPMCRubberRing[V]) next(chk *mpmcChank[V]) *mpmcChank[V] {
+	if next := chk.nextChank.Load(); next != nil {
+		return next
+	}
+	var newChank *mpmcChank[V]
+	select {
+	case free := <-r.freeChanks:
+		if r.epoch.safe(free.epoch) {
+			newChank = free.chank
+			newChank.base = chk.end()
+			newChank.nextChank.Store(nil)
+		} else {
+			r.recycle(free)
+		}
+	default:
+	}
+	if newChank == nil {
+		newChankSize, newChankCount := r.config.growStrategy(r.Capacity())
+		newChank = r.createChankChain(chk.end(), newChankSize, newChankCount)
+	}
+	if chk.nextChank.CompareAndSwap(nil, newChank) {
+		return newChank
+	}
+	// another goroutine has linked its chunks, ours were never visible to anyone
+	for unused := newChank; unused != nil; unused = unused.nextChank.Load() {
+		r.recycle(retiredChank[V]{chank: unused, epoch: epochReady})
+	}
+	return chk.nextChank.Load()
+}
+
+// advance moves the head or tail forward to chk unless it is already past base
+func (r *MPMCRubberRing[V]) advance(ptr *atomic.Pointer[mpmcChank[V]], base uint64, chk *mpmcChank[V]) {
+	for {
+		current := ptr.Load()
+		if current.base >= base || ptr.CompareAndSwap(current, chk) {
+			return
+		}
+	}
+}
+
+// retire is called by the consumer that took the last slot of chk
+func (r *MPMCRubberRing[V]) retire(chk *mpmcChank[V]) {
+	next := r.next(chk)
+	r.advance(&r.head, next.base, next)
+	r.advance(&r.tail, next.base, next)
+	r.recycle(retiredChank[V]{chank: chk, epoch: r.epoch.tryAdvance()})
+}
+
+func (r *MPMCRubberRing[V]) recycle(retired retiredChank[V]) {
+	select {
+	case r.freeChanks <- retired:
+	default:
+		r.capacity.Add(-int64(len(retired.chank.slots)))
+	}
+}
+
+func (r *MPMCRubberRing[V]) createChankChain(base uint64, chankSize, chankCount int) *mpmcChank[V] {
+	if chankCount < 1 {
+		chankCount = 1
+	}
+	if chankSize < 1 {
+		chankSize = 256
+	}
+	var chk *mpmcChank[V]
+	for i := chankCount - 1; i >= 0; i-- {
+		newChank := &mpmcChank[V]{
+			slots: make([]mpmcSlot[V], chankSize),
+			base:  base + uint64(i*chankSize),
+		}
+		newChank.nextChank.Store(chk)
+		chk = newChank
+	}
+	r.capacity.Add(int64(chankSize * chankCount))
+	return chk
+}
@@ -0,0 +1,188 @@
+package rubberring
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/goleak"
+)
+
+type MPMCRubberRingSuite struct {
+	suite.Suite
+	ring *MPMCRubberRing[int]
+}
+
+func (s *MPMCRubberRingSuite) SetupTest() {
+	s.ring = NewMPMCRubberRing[int](
+		WithStartChankSize(2),
+		WithStartChankCount(2),
+		WithGrowStrategy(func(int) (int, int) { return 2, 1 }),
+		WithPassiveChankBufferSize(4),
+	)
+}
+
+func (s *MPMCRubberRingSuite) TearDownTest() {
+	s.NoError(goleak.Find())
+	s.ring = nil
+}
+
+func (s *MPMCRubberRingSuite) TestPushPull() {
+	_, err := s.ring.TryPull()
+	s.Equal(io.EOF, err)
+
+	for round := range 3 {
+		for i := range 7 {
+			s.ring.Push(round*7 + i)
+		}
+		s.Equal(7, s.ring.Size())
+		for i := range 7 {
+			v, err := s.ring.TryPull()
+			s.NoError(err)
+			s.Equal(round*7+i, v)
+		}
+		_, err = s.ring.TryPull()
+		s.Equal(io.EOF, err)
+	}
+	s.LessOrEqual(s.ring.Capacity(), 4+4*2)
+}
+
+func (s *MPMCRubberRingSuite) TestConcurrent() {
+	const producers = 8
+	const consumers = 4
+	const perProducer = 5000
+	ctx := context.Background()
+
+	wg := &sync.WaitGroup{}
+	for p := range producers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perProducer {
+				s.ring.Push(p*perProducer + i)
+			}
+		}()
+	}
+
+	results := make(chan []int, consumers)
+	for c := range consumers {
+		go func() {
+			count := producers * perProducer / consumers
+			if c == 0 {
+				count += producers * perProducer % consumers
+			}
+			values := make([]int, 0, count)
+			for range count {
+				v, err := s.ring.Pull(ctx)
+				s.NoError(err)
+				values = append(values, v)
+			}
+			results <- values
+		}()
+	}
+	wg.Wait()
+
+	seen := make([]bool, producers*perProducer)
+	for range consumers {
+		values := <-results
+		last := make([]int, producers)
+		for p := range last {
+			last[p] = -1
+		}
+		for _, v := range values {
+			s.Require().False(seen[v], "duplicate %d", v)
+			seen[v] = true
+			// elements of one producer are pulled by one consumer in push order
+			s.Require().Greater(v, last[v/perProducer])
+			last[v/perProducer] = v
+		}
+	}
+	for v, ok := range seen {
+		s.Require().True(ok, "lost %d", v)
+	}
+	s.Equal(0, s.ring.Size())
+}
+
+func (s *MPMCRubberRingSuite) TestPullWaits() {
+	const consumers = 3
+	ctx := context.Background()
+	results := make(chan int, consumers)
+	for range consumers {
+		go func() {
+			v, err := s.ring.Pull(ctx)
+			s.NoError(err)
+			results <- v
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	for i := range consumers {
+		s.ring.Push(i)
+	}
+
+	sum := 0
+	for range consumers {
+		sum += <-results
+	}
+	s.Equal(0+1+2, sum)
+}
+
+func (s *MPMCRubberRingSuite) TestContextCancellation() {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := s.ring.Pull(ctx)
+	s.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func TestMPMCRubberRingSuite(t *testing.T) {
+	suite.Run(t, new(MPMCRubberRingSuite))
+}
+
+const benchmarkProducers = 64
+
+func benchmarkFanIn(b *testing.B, push func(int), pull func()) {
+	wg := &sync.WaitGroup{}
+	for p := range benchmarkProducers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := p; i < b.N; i += benchmarkProducers {
+				push(i)
+			}
+		}()
+	}
+	for range b.N {
+		pull()
+	}
+	wg.Wait()
+}
+
+func BenchmarkMPMCRubberRingFanIn(b *testing.B) {
+	ring := NewMPMCRubberRing[int]()
+	ctx := context.Background()
+	benchmarkFanIn(b, ring.Push, func() { ring.Pull(ctx) })
+}
+
+func BenchmarkSyncRubberRingFanIn(b *testing.B) {
+	ring := NewSyncRubberRing[int]()
+	ctx := context.Background()
+	benchmarkFanIn(b, ring.Push, func() { ring.Pull(ctx) })
+}
+
+func BenchmarkChannelFanIn(b *testing.B) {
+	ch := make(chan int, benchmarkBurst)
+	benchmarkFanIn(b, func(v int) { ch <- v }, func() { <-ch })
+}
+
+func BenchmarkMPMCRubberRingParallel(b *testing.B) {
+	ring := NewMPMCRubberRing[int]()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			ring.Push(1)
+			ring.TryPull()
+		}
+	})
+}
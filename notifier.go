@@ -0,0 +1,45 @@
+package rubberring
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type cacheLinePad [64]byte
+
+// notifier wakes up goroutines waiting for elements of lock-free rings,
+// producers pay only an atomic load while nobody waits
+type notifier struct {
+	waiters atomic.Int32
+	ch      chan struct{}
+}
+
+func newNotifier() *notifier {
+	return &notifier{ch: make(chan struct{}, 1)}
+}
+
+func (n *notifier) notify() {
+	if n.waiters.Load() == 0 {
+		return
+	}
+	select {
+	case n.ch <- struct{}{}:
+	default:
+	}
+}
+
+// wait blocks until notify is called or ready reports true,
+// ready is checked after the waiter is registered, so a notification can not be missed
+func (n *notifier) wait(ctx context.Context, ready func() bool) error {
+	n.waiters.Add(1)
+	defer n.waiters.Add(-1)
+	if ready() {
+		return nil
+	}
+	select {
+	case <-n.ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,197 @@
+package rubberring
+
+import (
+	"context"
+	"io"
+	"iter"
+	"sync"
+	"time"
+
+	syncutils "github.com/Skrip42/syncUtils"
+)
+
+type priorityConfig struct {
+	aging        time.Duration
+	levelOptions []applyConfigFunc
+}
+
+type applyPriorityConfigFunc func(c *priorityConfig)
+
+// WithAging promotes an element one level up for every period it waits,
+// so low priority elements are not starved
+func WithAging(period time.Duration) applyPriorityConfigFunc {
+	return func(c *priorityConfig) {
+		c.aging = period
+	}
+}
+
+// WithLevelOptions passes options to the RubberRing of every level,
+// the clock of WithClock is also used for aging
+func WithLevelOptions(options ...applyConfigFunc) applyPriorityConfigFunc {
+	return func(c *priorityConfig) {
+		c.levelOptions = append(c.levelOptions, options...)
+	}
+}
+
+type priorityItem[V any] struct {
+	value V
+	// since is the time the element was pushed or promoted to its level
+	since time.Time
+}
+
+// PriorityRing holds a RubberRing per priority level and always pulls from the highest non-empty one,
+// the level with the greatest number has the highest priority
+type PriorityRing[V any] struct {
+	mu     *sync.Mutex
+	cond   *syncutils.Cond
+	levels []*RubberRing[priorityItem[V]]
+	size   int
+	aging  time.Duration
+	clock  Clock
+}
+
+func NewPriorityRing[V any](levels int, options ...applyPriorityConfigFunc) *PriorityRing[V] {
+	config := priorityConfig{}
+	for _, option := range options {
+		option(&config)
+	}
+	if levels < 1 {
+		levels = 1
+	}
+	r := &PriorityRing[V]{
+		mu:     &sync.Mutex{},
+		cond:   syncutils.NewCond(),
+		levels: make([]*RubberRing[priorityItem[V]], levels),
+		aging:  config.aging,
+	}
+	for i := range r.levels {
+		r.levels[i] = NewRubberRing[priorityItem[V]](config.levelOptions...)
+	}
+	r.clock = r.levels[0].config.clock
+	return r
+}
+
+func (r *PriorityRing[V]) Levels() int {
+	return len(r.levels)
+}
+
+func (r *PriorityRing[V]) Size() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.size
+}
+
+// Stat returns the stat of every level
+func (r *PriorityRing[V]) Stat() []RubberRingStat {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stat := make([]RubberRingStat, len(r.levels))
+	for i, level := range r.levels {
+		stat[i] = level.Stat()
+	}
+	return stat
+}
+
+// Push puts the element to the level prio, out of range priorities are clamped
+func (r *PriorityRing[V]) Push(prio int, value V) {
+	prio = max(0, min(prio, len(r.levels)-1))
+	r.mu.Lock()
+	r.levels[prio].Push(priorityItem[V]{value: value, since: r.clock.Now()})
+	r.size++
+	r.cond.Signal()
+	r.mu.Unlock()
+}
+
+// TryPull returns io.EOF instead of waiting if the ring is empty
+func (r *PriorityRing[V]) TryPull() (V, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.pullLocked(); ok {
+		return v, nil
+	}
+	var v V
+	return v, io.EOF
+}
+
+func (r *PriorityRing[V]) Pull(ctx context.Context) (V, error) {
+	var v V
+	r.mu.Lock()
+	for {
+		if v, ok := r.pullLocked(); ok {
+			r.mu.Unlock()
+			return v, nil
+		}
+		wait := r.cond.Wait()
+		r.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			select {
+			case <-wait:
+				// the signal was meant for an element, pass it to another waiter
+				r.mu.Lock()
+				r.cond.Signal()
+				r.mu.Unlock()
+			default:
+			}
+			return v, ctx.Err()
+		}
+		r.mu.Lock()
+	}
+}
+
+func (r *PriorityRing[V]) Elements(ctx context.Context) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for {
+			v, err := r.Pull(ctx)
+			if err != nil {
+				return
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// pullLocked must be called with mu held, it returns false if no element is left,
+// the size follows the levels, so the elements skipped because of TTL are counted too
+func (r *PriorityRing[V]) pullLocked() (V, bool) {
+	if r.size == 0 {
+		var v V
+		return v, false
+	}
+	if r.aging > 0 {
+		r.promoteLocked()
+	}
+	for i := len(r.levels) - 1; i >= 0; i-- {
+		level := r.levels[i]
+		size := level.Size()
+		item, err := level.Pull()
+		r.size -= size - level.Size()
+		if err == nil {
+			return item.value, true
+		}
+	}
+	var v V
+	return v, false
+}
+
+// promoteLocked moves elements that waited for the aging period at the head of their level one level up,
+// an element that waited for several periods is moved several levels
+func (r *PriorityRing[V]) promoteLocked() {
+	now := r.clock.Now()
+	for i := 0; i < len(r.levels)-1; i++ {
+		for {
+			size := r.levels[i].Size()
+			item, err := r.levels[i].Peek()
+			r.size -= size - r.levels[i].Size()
+			if err != nil || now.Sub(item.since) < r.aging {
+				break
+			}
+			deadline := r.levels[i].discard()
+			item.since = item.since.Add(r.aging)
+			r.levels[i+1].pushDeadline(item, deadline)
+		}
+	}
+}
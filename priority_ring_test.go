@@ -0,0 +1,180 @@
+package rubberring
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/goleak"
+)
+
+type PriorityRingSuite struct {
+	suite.Suite
+	ring *PriorityRing[int]
+}
+
+func (s *PriorityRingSuite) SetupTest() {
+	s.ring = NewPriorityRing[int](3, WithLevelOptions(WithStartChankSize(4), WithStartChankCount(1)))
+}
+
+func (s *PriorityRingSuite) TearDownTest() {
+	s.NoError(goleak.Find())
+	s.ring = nil
+}
+
+func (s *PriorityRingSuite) TestHighestLevelFirst() {
+	s.ring.Push(0, 1)
+	s.ring.Push(2, 2)
+	s.ring.Push(1, 3)
+	s.ring.Push(2, 4)
+	s.ring.Push(10, 5)
+	s.ring.Push(-1, 6)
+	s.Equal(6, s.ring.Size())
+
+	result := []int{}
+	for {
+		v, err := s.ring.TryPull()
+		if err != nil {
+			s.Equal(io.EOF, err)
+			break
+		}
+		result = append(result, v)
+	}
+	s.Equal([]int{2, 4, 5, 3, 1, 6}, result)
+}
+
+func (s *PriorityRingSuite) TestStat() {
+	s.ring.Push(0, 1)
+	s.ring.Push(2, 2)
+	s.ring.Push(2, 3)
+
+	stat := s.ring.Stat()
+	s.Len(stat, 3)
+	s.Equal(1, stat[0].Size)
+	s.Equal(0, stat[1].Size)
+	s.Equal(2, stat[2].Size)
+}
+
+func (s *PriorityRingSuite) TestAging() {
+	clock := newFakeClock()
+	s.ring = NewPriorityRing[int](3, WithAging(time.Second), WithLevelOptions(WithClock(clock)))
+
+	s.ring.Push(0, 1)
+	clock.Advance(time.Second)
+	s.ring.Push(1, 2)
+	s.ring.Push(2, 3)
+
+	v, _ := s.ring.TryPull()
+	s.Equal(3, v)
+	v, _ = s.ring.TryPull()
+	s.Equal(2, v)
+
+	s.ring.Push(1, 4)
+	s.ring.Push(0, 5)
+	clock.Advance(time.Second)
+	s.ring.Push(2, 6)
+
+	// 1 waited for two periods and reached the highest level behind 6
+	// 4 waited for one period and reached the highest level too
+	v, _ = s.ring.TryPull()
+	s.Equal(6, v)
+	v, _ = s.ring.TryPull()
+	s.Equal(1, v)
+	v, _ = s.ring.TryPull()
+	s.Equal(4, v)
+	v, _ = s.ring.TryPull()
+	s.Equal(5, v)
+}
+
+func (s *PriorityRingSuite) TestPullWaits() {
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		s.ring.Push(1, 7)
+	}()
+	v, err := s.ring.Pull(context.Background())
+	s.NoError(err)
+	s.Equal(7, v)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = s.ring.Pull(ctx)
+	s.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func (s *PriorityRingSuite) TestConcurrent() {
+	const producers = 4
+	const perProducer = 1000
+
+	wg := &sync.WaitGroup{}
+	for p := range producers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perProducer {
+				s.ring.Push(p%3, i)
+			}
+		}()
+	}
+
+	pulled := 0
+	for range s.ring.Elements(context.Background()) {
+		pulled++
+		if pulled == producers*perProducer {
+			break
+		}
+	}
+	wg.Wait()
+	s.Equal(0, s.ring.Size())
+}
+
+func (s *PriorityRingSuite) TestElementTTL() {
+	clock := newFakeClock()
+	s.ring = NewPriorityRing[int](3, WithLevelOptions(WithElementTTL(time.Second), WithClock(clock)))
+	s.ring.Push(2, 1)
+	s.ring.Push(0, 2)
+	clock.Advance(time.Second)
+	s.ring.Push(0, 3)
+
+	v, err := s.ring.TryPull()
+	s.NoError(err)
+	s.Equal(3, v)
+	s.Equal(0, s.ring.Size())
+	_, err = s.ring.TryPull()
+	s.Equal(io.EOF, err)
+}
+
+func (s *PriorityRingSuite) TestAgingKeepsDeadline() {
+	clock := newFakeClock()
+	s.ring = NewPriorityRing[int](2, WithAging(time.Second),
+		WithLevelOptions(WithElementTTL(2*time.Second), WithClock(clock)))
+	s.ring.Push(0, 1)
+	clock.Advance(time.Second)
+	s.ring.Push(0, 2)
+	clock.Advance(time.Second)
+
+	// 1 expires at the head of its level, 2 is promoted
+	v, err := s.ring.TryPull()
+	s.NoError(err)
+	s.Equal(2, v)
+
+	s.ring.Push(0, 3)
+	clock.Advance(time.Second)
+	s.ring.Push(1, 4)
+	v, err = s.ring.TryPull()
+	s.NoError(err)
+	s.Equal(4, v)
+	s.Equal(1, s.ring.Stat()[1].Size)
+
+	// 3 is promoted with its deadline, so it expires as it would on its own level
+	clock.Advance(time.Second)
+	_, err = s.ring.TryPull()
+	s.Equal(io.EOF, err)
+	s.Equal(0, s.ring.Size())
+}
+
+func TestPriorityRingSuite(t *testing.T) {
+	suite.Run(t, new(PriorityRingSuite))
+}
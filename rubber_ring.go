@@ -1,17 +1,30 @@
 package rubberring
 
 import (
+	"errors"
 	"io"
 	"iter"
+	"time"
 )
 
+var ErrOffsetOutOfRange = errors.New("rubberring: offset is out of the retained range")
+
 type GrowStrategy func(capacity int) (newChankSize, newChankCount int)
 
 type chank[V any] struct {
-	data      []V
+	data []V
+	// deadlines are allocated only for rings with expiring elements, 0 means no deadline
+	deadlines []int64
+	// segment is set only for a chunk of SpillRing written to disk, its data is nil then
+	segment   *chankSegment
 	nextChank *chank[V]
 }
 
+type chankSegment struct {
+	path string
+	size int
+}
+
 type RubberRing[V any] struct {
 	startChank    *chank[V]
 	startPosition int
@@ -21,10 +34,27 @@ type RubberRing[V any] struct {
 	size          int
 	capacity      int
 	config        config
+	// sharedPool means that freeChanks is shared with other rings,
+	// so chunks taken from it or put to it change the capacity of the ring
+	sharedPool bool
+	// retainChank starts the chain of chunks with pulled elements kept for SeekTo,
+	// retainBase is the offset of its first element. Both are touched only by the pulling side.
+	retainChank *chank[V]
+	retainBase  uint64
+	// offset is the offset of the next pushed element, headOffset is the offset of the first element
+	offset     uint64
+	headOffset uint64
+	// expiring is set once an element with TTL is pushed, expired and onExpired belong to the pulling side
+	expiring  bool
+	expired   uint64
+	onExpired func(V)
 }
 
 func NewRubberRing[V any](options ...applyConfigFunc) *RubberRing[V] {
-	config := defaultConfig
+	return newRubberRing[V](defaultConfig, options)
+}
+
+func newRubberRing[V any](config config, options []applyConfigFunc) *RubberRing[V] {
 	for _, option := range options {
 		option(&config)
 	}
@@ -40,11 +70,59 @@ func NewRubberRing[V any](options ...applyConfigFunc) *RubberRing[V] {
 	)
 	rr.startChank = chanks
 	rr.endChank = chanks
+	rr.retainChank = chanks
 	rr.capacity = capacity
+	rr.expiring = config.ttl > 0
 
 	return rr
 }
 
+// newPooledRubberRing creates a ring that takes chunks from the pool shared with other rings
+// and puts drained chunks back to it
+func newPooledRubberRing[V any](config config, pool chan *chank[V]) *RubberRing[V] {
+	rr := &RubberRing[V]{
+		config:     config,
+		freeChanks: pool,
+		sharedPool: true,
+		expiring:   config.ttl > 0,
+	}
+	var last *chank[V]
+	for range config.startChankCount {
+		var chk *chank[V]
+		select {
+		case chk = <-pool:
+		default:
+			chk = createNewChankChain[V](config.startChankSize, 1)
+		}
+		if last == nil {
+			rr.startChank = chk
+		} else {
+			last.nextChank = chk
+		}
+		last = chk
+		rr.capacity += len(chk.data)
+	}
+	rr.endChank = rr.startChank
+	rr.retainChank = rr.startChank
+	return rr
+}
+
+// release puts all chunks of the ring to the shared pool, the ring must not be used afterwards
+func (r *RubberRing[V]) release() {
+	for chk := r.retainChank; chk != nil; {
+		next := chk.nextChank
+		chk.nextChank = nil
+		clear(chk.data)
+		select {
+		case r.freeChanks <- chk:
+		default:
+		}
+		chk = next
+	}
+	r.startChank, r.endChank, r.retainChank = nil, nil, nil
+	r.size, r.capacity = 0, 0
+}
+
 func (r *RubberRing[V]) Size() int {
 	return r.size
 }
@@ -58,31 +136,205 @@ func (r *RubberRing[V]) Stat() RubberRingStat {
 }
 
 func (r *RubberRing[V]) Pull() (V, error) {
+	_, el, err := r.PullWithOffset()
+	return el, err
+}
+
+// Peek returns the first element without pulling it, expired elements at the start are dropped as Pull does
+func (r *RubberRing[V]) Peek() (V, error) {
 	var el V
+	r.Sweep()
 	if r.size == 0 {
 		return el, io.EOF
 	}
-	el = r.startChank.data[r.startPosition]
-	r.startPosition++
+	return r.startChank.data[r.startPosition], nil
+}
+
+// PullWithOffset works like Pull and also returns the offset of the element
+func (r *RubberRing[V]) PullWithOffset() (uint64, V, error) {
+	var now int64
+	if r.expiring {
+		now = r.config.clock.Now().UnixNano()
+	}
+	for r.size > 0 {
+		offset := r.headOffset
+		expired := r.expiring && r.headExpired(now)
+		el, released := r.pullElement()
+		r.size--
+		r.capacity -= released
+		if expired {
+			r.expire(el)
+			continue
+		}
+		return offset, el, nil
+	}
+	var el V
+	return r.headOffset, el, io.EOF
+}
+
+// PushWithTTL puts the element that Pull skips after ttl
+func (r *RubberRing[V]) PushWithTTL(el V, ttl time.Duration) {
+	r.expiring = true
+	r.capacity += r.pushElement(el, r.deadline(ttl), r.capacity)
+	r.size++
+}
+
+// OnExpired sets the callback that receives the elements skipped because of TTL,
+// it is called while the ring is locked, so it must not use the ring
+func (r *RubberRing[V]) OnExpired(fn func(V)) {
+	r.onExpired = fn
+}
+
+// Expired returns the number of elements skipped because of TTL
+func (r *RubberRing[V]) Expired() uint64 {
+	return r.expired
+}
+
+// Sweep drops the expired elements from the start of the ring, returns their number
+func (r *RubberRing[V]) Sweep() int {
+	if !r.expiring {
+		return 0
+	}
+	now := r.config.clock.Now().UnixNano()
+	swept := 0
+	for r.size > 0 && r.headExpired(now) {
+		el, released := r.pullElement()
+		r.size--
+		r.capacity -= released
+		r.expire(el)
+		swept++
+	}
+	return swept
+}
+
+// discard drops the first element returned by Peek, even if it has expired since,
+// and returns its deadline for pushDeadline
+func (r *RubberRing[V]) discard() int64 {
+	var deadline int64
+	if deadlines := r.startChank.deadlines; deadlines != nil {
+		deadline = deadlines[r.startPosition]
+	}
+	_, released := r.pullElement()
 	r.size--
+	r.capacity -= released
+	return deadline
+}
+
+// pushDeadline puts the element moved from another ring keeping its deadline
+func (r *RubberRing[V]) pushDeadline(el V, deadline int64) {
+	if deadline != 0 {
+		r.expiring = true
+	}
+	r.capacity += r.pushElement(el, deadline, r.capacity)
+	r.size++
+}
+
+func (r *RubberRing[V]) deadline(ttl time.Duration) int64 {
+	if !r.expiring || ttl <= 0 {
+		return 0
+	}
+	return r.config.clock.Now().Add(ttl).UnixNano()
+}
+
+func (r *RubberRing[V]) headExpired(now int64) bool {
+	deadlines := r.startChank.deadlines
+	return deadlines != nil && deadlines[r.startPosition] != 0 && deadlines[r.startPosition] <= now
+}
+
+func (r *RubberRing[V]) expire(el V) {
+	r.expired++
+	if r.onExpired != nil {
+		r.onExpired(el)
+	}
+}
+
+// OffsetRange returns the offset of the first element that can be read again with SeekTo
+// and the offset of the next pushed element
+func (r *RubberRing[V]) OffsetRange() (first, last uint64) {
+	first = r.retainBase
+	if retention := uint64(r.config.retention); r.headOffset-first > retention {
+		first = r.headOffset - retention
+	}
+	return first, r.offset
+}
+
+// SeekTo moves the start of the ring to the offset, back to elements kept by WithRetention
+// or forward skipping elements
+func (r *RubberRing[V]) SeekTo(offset uint64) error {
+	first, last := r.OffsetRange()
+	if offset < first || offset > last {
+		return ErrOffsetOutOfRange
+	}
+	chk, base := r.retainChank, r.retainBase
+	for chk != r.endChank && offset-base >= uint64(len(chk.data)) {
+		base += uint64(len(chk.data))
+		chk = chk.nextChank
+	}
+	r.startChank = chk
+	r.startPosition = int(offset - base)
+	r.headOffset = offset
+	r.size = int(last - offset)
+	r.capacity -= r.trimRetained()
+	return nil
+}
+
+// pullElement takes the element from the start of the chain, it touches only the start of the chain,
+// so it can run concurrently with pushElement. Returns the capacity released by the ring.
+func (r *RubberRing[V]) pullElement() (V, int) {
+	el := r.startChank.data[r.startPosition]
+	r.startPosition++
+	r.headOffset++
+	released := 0
 	if r.startPosition >= len(r.startChank.data) {
-		newStartChank := r.startChank.nextChank
-		r.startChank.nextChank = nil
+		r.startChank = r.startChank.nextChank
+		r.startPosition = 0
+		released = r.trimRetained()
+	}
+	return el, released
+}
+
+// trimRetained frees the chunks before the start of the chain that are out of the retention window.
+// Returns the capacity released by the ring.
+func (r *RubberRing[V]) trimRetained() int {
+	released := 0
+	retention := uint64(r.config.retention)
+	for r.retainChank != r.startChank &&
+		r.headOffset-(r.retainBase+uint64(len(r.retainChank.data))) >= retention {
+		chk := r.retainChank
+		r.retainChank = chk.nextChank
+		r.retainBase += uint64(len(chk.data))
+		chk.nextChank = nil
 		select {
-		case r.freeChanks <- r.startChank:
+		case r.freeChanks <- chk:
+			if r.sharedPool {
+				released += len(chk.data)
+			}
 		default:
-			r.capacity -= len(r.startChank.data)
+			released += len(chk.data)
 		}
-		r.startChank = newStartChank
-		r.startPosition = 0
 	}
-	return el, nil
+	return released
 }
 
 func (r *RubberRing[V]) Push(el V) {
+	r.capacity += r.pushElement(el, r.deadline(r.config.ttl), r.capacity)
+	r.size++
+}
+
+// pushElement puts the element to the end of the chain and links the next chunk as soon as
+// the last one is filled, so a reader never reaches the end of a chunk without a next one.
+// Returns the capacity added to the ring.
+func (r *RubberRing[V]) pushElement(el V, deadline int64, capacity int) int {
 	r.endChank.data[r.endPosition] = el
+	if r.expiring {
+		if r.endChank.deadlines == nil {
+			r.endChank.deadlines = make([]int64, len(r.endChank.data))
+		}
+		r.endChank.deadlines[r.endPosition] = deadline
+	}
 	r.endPosition++
-	r.size++
+	r.offset++
+	grown := 0
 	if r.endPosition >= len(r.endChank.data) {
 		var newEndChank *chank[V]
 		if r.endChank.nextChank != nil {
@@ -90,19 +342,23 @@ func (r *RubberRing[V]) Push(el V) {
 		} else {
 			select {
 			case newEndChank = <-r.freeChanks:
+				if r.sharedPool {
+					grown = len(newEndChank.data)
+				}
 			default:
-				newChankSize, newChankCount := r.config.growStrategy(r.capacity)
+				newChankSize, newChankCount := r.config.growStrategy(capacity)
 				newChanks := createNewChankChain[V](
 					newChankSize, newChankCount,
 				)
 				newEndChank = newChanks
-				r.capacity += newChankSize * newChankCount
+				grown = newChankSize * newChankCount
 			}
 		}
 		r.endChank.nextChank = newEndChank
 		r.endChank = newEndChank
 		r.endPosition = 0
 	}
+	return grown
 }
 
 func (r *RubberRing[V]) Elements() iter.Seq[V] {
@@ -119,6 +375,79 @@ func (r *RubberRing[V]) Elements() iter.Seq[V] {
 	}
 }
 
+func (r *RubberRing[V]) ToSlice() []V {
+	return r.AppendTo(make([]V, 0, r.size))
+}
+
+func (r *RubberRing[V]) AppendTo(dst []V) []V {
+	for v := range r.all() {
+		dst = append(dst, v)
+	}
+	return dst
+}
+
+func (r *RubberRing[V]) Clone() *RubberRing[V] {
+	clone := &RubberRing[V]{
+		startPosition: r.startPosition,
+		endPosition:   r.endPosition,
+		freeChanks:    make(chan *chank[V], cap(r.freeChanks)),
+		size:          r.size,
+		capacity:      r.capacity,
+		config:        r.config,
+		retainBase:    r.headOffset - uint64(r.startPosition),
+		offset:        r.offset,
+		headOffset:    r.headOffset,
+		expiring:      r.expiring,
+		expired:       r.expired,
+		onExpired:     r.onExpired,
+	}
+	// retained chunks are not cloned
+	for chk := r.retainChank; chk != r.startChank; chk = chk.nextChank {
+		clone.capacity -= len(chk.data)
+	}
+	var prev *chank[V]
+	for chk := r.startChank; chk != nil; chk = chk.nextChank {
+		newChank := &chank[V]{data: make([]V, len(chk.data))}
+		copy(newChank.data, chk.data)
+		if chk.deadlines != nil {
+			newChank.deadlines = append([]int64(nil), chk.deadlines...)
+		}
+		if prev == nil {
+			clone.startChank = newChank
+			clone.retainChank = newChank
+		} else {
+			prev.nextChank = newChank
+		}
+		if chk == r.endChank {
+			clone.endChank = newChank
+		}
+		prev = newChank
+	}
+	for range len(r.freeChanks) {
+		chk := <-r.freeChanks
+		r.freeChanks <- chk
+		clone.freeChanks <- &chank[V]{data: make([]V, len(chk.data))}
+	}
+	return clone
+}
+
+// all iterates over the elements without pulling them out of the ring
+func (r *RubberRing[V]) all() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		chk, position := r.startChank, r.startPosition
+		for range r.size {
+			if position >= len(chk.data) {
+				chk = chk.nextChank
+				position = 0
+			}
+			if !yield(chk.data[position]) {
+				return
+			}
+			position++
+		}
+	}
+}
+
 func createNewChankChain[V any](
 	chankSize int,
 	chankCount int,
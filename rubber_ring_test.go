@@ -3,6 +3,7 @@ package rubberring
 import (
 	"io"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -78,6 +79,124 @@ func (s *RubberRingSuite) TestPushPull() {
 	s.Equal(0, s.ring.Size())
 }
 
+func (s *RubberRingSuite) TestPeek() {
+	_, err := s.ring.Peek()
+	s.Equal(io.EOF, err)
+
+	s.ring.Push(1)
+	s.ring.Push(2)
+	val, err := s.ring.Peek()
+	s.NoError(err)
+	s.Equal(1, val)
+	s.Equal(2, s.ring.Size())
+}
+
+func (s *RubberRingSuite) TestOffsets() {
+	for i := range 5 {
+		s.ring.Push(i * 10)
+	}
+	offset, val, err := s.ring.PullWithOffset()
+	s.NoError(err)
+	s.Equal(uint64(0), offset)
+	s.Equal(0, val)
+	offset, val, err = s.ring.PullWithOffset()
+	s.NoError(err)
+	s.Equal(uint64(1), offset)
+	s.Equal(10, val)
+
+	first, last := s.ring.OffsetRange()
+	s.Equal(uint64(2), first)
+	s.Equal(uint64(5), last)
+
+	s.ErrorIs(s.ring.SeekTo(1), ErrOffsetOutOfRange)
+	s.ErrorIs(s.ring.SeekTo(6), ErrOffsetOutOfRange)
+	s.NoError(s.ring.SeekTo(4))
+	s.Equal(1, s.ring.Size())
+	offset, val, _ = s.ring.PullWithOffset()
+	s.Equal(uint64(4), offset)
+	s.Equal(40, val)
+}
+
+func (s *RubberRingSuite) TestRetention() {
+	s.ring = NewRubberRing[int](
+		WithStartChankSize(4),
+		WithStartChankCount(1),
+		WithGrowStrategy(func(int) (int, int) { return 4, 1 }),
+		WithPassiveChankBufferSize(1),
+		WithRetention(5),
+	)
+	for i := range 20 {
+		s.ring.Push(i)
+	}
+	for range 18 {
+		s.ring.Pull()
+	}
+	first, last := s.ring.OffsetRange()
+	s.Equal(uint64(13), first)
+	s.Equal(uint64(20), last)
+	// retained chunks are trimmed when the start moves to the next chunk
+	s.Equal(20, s.ring.Capacity())
+
+	s.NoError(s.ring.SeekTo(13))
+	s.Equal(7, s.ring.Size())
+	s.Equal([]int{13, 14, 15, 16, 17, 18, 19}, s.ring.ToSlice())
+	s.Equal([]int{13, 14, 15, 16, 17, 18, 19}, s.ring.Clone().ToSlice())
+
+	s.NoError(s.ring.SeekTo(20))
+	s.Equal(0, s.ring.Size())
+	first, _ = s.ring.OffsetRange()
+	s.Equal(uint64(15), first)
+	s.ring.Push(20)
+	offset, val, err := s.ring.PullWithOffset()
+	s.NoError(err)
+	s.Equal(uint64(20), offset)
+	s.Equal(20, val)
+}
+
+func (s *RubberRingSuite) TestElementTTL() {
+	clock := newFakeClock()
+	s.ring = NewRubberRing[int](WithElementTTL(time.Second), WithClock(clock))
+	expired := []int{}
+	s.ring.OnExpired(func(v int) { expired = append(expired, v) })
+
+	s.ring.Push(1)
+	s.ring.Push(2)
+	clock.Advance(500 * time.Millisecond)
+	s.ring.Push(3)
+	s.ring.PushWithTTL(4, time.Hour)
+	clock.Advance(500 * time.Millisecond)
+
+	val, err := s.ring.Pull()
+	s.NoError(err)
+	s.Equal(3, val)
+	s.Equal([]int{1, 2}, expired)
+	s.Equal(uint64(2), s.ring.Expired())
+
+	clock.Advance(time.Second)
+	val, err = s.ring.Pull()
+	s.NoError(err)
+	s.Equal(4, val)
+}
+
+func (s *RubberRingSuite) TestPushWithTTLAndSweep() {
+	clock := newFakeClock()
+	s.ring = NewRubberRing[int](WithClock(clock), WithStartChankSize(2), WithStartChankCount(1))
+	s.ring.Push(0)
+	for i := 1; i <= 4; i++ {
+		s.ring.PushWithTTL(i, time.Duration(i)*time.Second)
+	}
+	s.ring.Push(5)
+	clock.Advance(2 * time.Second)
+
+	// the element without TTL at the start stops the sweep
+	s.Equal(0, s.ring.Sweep())
+	val, _ := s.ring.Pull()
+	s.Equal(0, val)
+	s.Equal(2, s.ring.Sweep())
+	s.Equal(3, s.ring.Size())
+	s.Equal([]int{3, 4, 5}, s.ring.ToSlice())
+}
+
 func (s *RubberRingSuite) TestCapacityGrowth() {
 	rr := NewRubberRing[int](
 		WithStartChankSize(2),
@@ -124,6 +243,65 @@ func (s *RubberRingSuite) TestStat() {
 	s.Len(stat.ActiveChanksSize, 1)
 }
 
+func (s *RubberRingSuite) TestToSlice() {
+	rr := NewRubberRing[int](
+		WithStartChankSize(2),
+		WithStartChankCount(1),
+	)
+	s.Empty(rr.ToSlice())
+
+	for i := 0; i < 7; i++ {
+		rr.Push(i)
+	}
+	_, err := rr.Pull()
+	s.NoError(err)
+
+	s.Equal([]int{1, 2, 3, 4, 5, 6}, rr.ToSlice())
+	s.Equal([]int{-1, 1, 2, 3, 4, 5, 6}, rr.AppendTo([]int{-1}))
+	s.Equal(6, rr.Size())
+}
+
+func (s *RubberRingSuite) TestClone() {
+	rr := NewRubberRing[int](
+		WithStartChankSize(2),
+		WithStartChankCount(2),
+		WithPassiveChankBufferSize(2),
+	)
+	for i := 0; i < 9; i++ {
+		rr.Push(i)
+	}
+	for i := 0; i < 4; i++ {
+		_, err := rr.Pull()
+		s.NoError(err)
+	}
+
+	clone := rr.Clone()
+	s.Equal(rr.Stat(), clone.Stat())
+	s.Equal(rr.ToSlice(), clone.ToSlice())
+
+	rr.Push(100)
+	clone.Push(200)
+	_, err := clone.Pull()
+	s.NoError(err)
+
+	s.Equal([]int{4, 5, 6, 7, 8, 100}, rr.ToSlice())
+	s.Equal([]int{5, 6, 7, 8, 200}, clone.ToSlice())
+}
+
+func (s *RubberRingSuite) TestPeekDropsExpired() {
+	clock := newFakeClock()
+	s.ring = NewRubberRing[int](WithElementTTL(time.Second), WithClock(clock))
+	s.ring.Push(1)
+	clock.Advance(time.Second)
+	s.ring.Push(2)
+
+	val, err := s.ring.Peek()
+	s.NoError(err)
+	s.Equal(2, val)
+	s.Equal(1, s.ring.Size())
+	s.Equal(uint64(1), s.ring.Expired())
+}
+
 func TestRubberRingSuite(t *testing.T) {
 	suite.Run(t, new(RubberRingSuite))
 }
@@ -0,0 +1,163 @@
+package rubberring
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// PullAny waits until any of the rings has an element and pulls it,
+// rings that go first in the arguments have priority. Returns the index of the ring.
+func PullAny[V any](ctx context.Context, rings ...*SyncRubberRing[V]) (int, V, error) {
+	return pullAny(ctx, rings, func() (int, V, bool) {
+		for i, ring := range rings {
+			if v, err := ring.TryPull(); err == nil {
+				return i, v, true
+			}
+		}
+		var v V
+		return -1, v, false
+	})
+}
+
+func pullAny[V any](
+	ctx context.Context,
+	rings []*SyncRubberRing[V],
+	tryPull func() (int, V, bool),
+) (int, V, error) {
+	if i, v, ok := tryPull(); ok {
+		return i, v, nil
+	}
+
+	ch := make(chan struct{}, 1)
+	for _, ring := range rings {
+		ring.watch(ch)
+	}
+	defer func() {
+		for _, ring := range rings {
+			ring.unwatch(ch)
+		}
+	}()
+
+	for {
+		// watchers are registered before the check, so an element pushed after it is notified
+		if i, v, ok := tryPull(); ok {
+			return i, v, nil
+		}
+		select {
+		case <-ch:
+		case <-leaseTimer(rings):
+		case <-ctx.Done():
+			var v V
+			return -1, v, ctx.Err()
+		}
+	}
+}
+
+// leaseTimer fires at the earliest lease deadline of the rings, when a timed out element comes back,
+// it is nil if nothing is leased
+func leaseTimer[V any](rings []*SyncRubberRing[V]) <-chan time.Time {
+	var clock Clock
+	var wait time.Duration
+	for _, ring := range rings {
+		if timeout, ok := ring.leaseTimeout(); ok && (clock == nil || timeout < wait) {
+			clock, wait = ring.ring.config.clock, timeout
+		}
+	}
+	if clock == nil {
+		return nil
+	}
+	return clock.After(wait)
+}
+
+type selectorConfig struct {
+	weights []int
+}
+
+type applySelectorFunc func(c *selectorConfig)
+
+// WithWeights makes the selector share pulls between non-empty rings in proportion to weights
+// (smooth weighted round-robin), by default rings are served by priority in their order
+func WithWeights(weights ...int) applySelectorFunc {
+	return func(c *selectorConfig) {
+		c.weights = weights
+	}
+}
+
+// Selector pulls elements from several rings with priority or weighted ordering
+type Selector[V any] struct {
+	rings   []*SyncRubberRing[V]
+	weights []int
+
+	mu      *sync.Mutex
+	current []int
+	// order is reused by every pull to sort the non-empty rings
+	order []int
+}
+
+func NewSelector[V any](rings []*SyncRubberRing[V], options ...applySelectorFunc) *Selector[V] {
+	config := selectorConfig{}
+	for _, option := range options {
+		option(&config)
+	}
+	s := &Selector[V]{
+		rings: rings,
+		mu:    &sync.Mutex{},
+	}
+	if config.weights != nil {
+		s.weights = make([]int, len(rings))
+		for i := range rings {
+			s.weights[i] = 1
+			if i < len(config.weights) && config.weights[i] > 0 {
+				s.weights[i] = config.weights[i]
+			}
+		}
+		s.current = make([]int, len(rings))
+		s.order = make([]int, 0, len(rings))
+	}
+	return s
+}
+
+// Pull waits until any of the rings has an element and pulls it, returns the index of the ring
+func (s *Selector[V]) Pull(ctx context.Context) (int, V, error) {
+	if s.weights == nil {
+		return PullAny(ctx, s.rings...)
+	}
+	return pullAny(ctx, s.rings, s.tryPullWeighted)
+}
+
+func (s *Selector[V]) tryPullWeighted() (int, V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// only non-empty rings take part, an empty ring does not collect credit,
+	// so it does not take every pull when it gets elements again.
+	// Leased rings take part anyway, a timed out element is returned by the pull.
+	s.order = s.order[:0]
+	total := 0
+	for i, ring := range s.rings {
+		if ring.Size() == 0 && !ring.leasing.Load() {
+			s.current[i] = min(s.current[i], 0)
+			continue
+		}
+		s.order = append(s.order, i)
+		total += s.weights[i]
+	}
+	slices.SortStableFunc(s.order, func(a, b int) int {
+		return (s.current[b] + s.weights[b]) - (s.current[a] + s.weights[a])
+	})
+	for _, i := range s.order {
+		v, err := s.rings[i].TryPull()
+		if err != nil {
+			continue
+		}
+		for _, j := range s.order {
+			s.current[j] += s.weights[j]
+		}
+		s.current[i] -= total
+		return i, v, true
+	}
+	var v V
+	return -1, v, false
+}
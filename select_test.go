@@ -0,0 +1,157 @@
+package rubberring
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/goleak"
+)
+
+type SelectSuite struct {
+	suite.Suite
+	rings []*SyncRubberRing[int]
+}
+
+func (s *SelectSuite) SetupTest() {
+	s.rings = []*SyncRubberRing[int]{
+		NewSyncRubberRing[int](),
+		NewSyncRubberRing[int](),
+		NewSyncRubberRing[int](),
+	}
+}
+
+func (s *SelectSuite) TearDownTest() {
+	s.NoError(goleak.Find())
+	s.rings = nil
+}
+
+func (s *SelectSuite) TestPullAnyPriority() {
+	s.rings[2].Push(3)
+	s.rings[1].Push(2)
+
+	idx, v, err := PullAny(context.Background(), s.rings...)
+	s.NoError(err)
+	s.Equal(1, idx)
+	s.Equal(2, v)
+
+	idx, v, err = PullAny(context.Background(), s.rings...)
+	s.NoError(err)
+	s.Equal(2, idx)
+	s.Equal(3, v)
+}
+
+func (s *SelectSuite) TestPullAnyWaits() {
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		s.rings[2].Push(7)
+	}()
+	idx, v, err := PullAny(context.Background(), s.rings...)
+	s.NoError(err)
+	s.Equal(2, idx)
+	s.Equal(7, v)
+
+	for _, ring := range s.rings {
+		s.Equal(int32(0), ring.watching.Load())
+	}
+}
+
+func (s *SelectSuite) TestPullAnyCancel() {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	idx, _, err := PullAny(ctx, s.rings...)
+	s.ErrorIs(err, context.DeadlineExceeded)
+	s.Equal(-1, idx)
+}
+
+func (s *SelectSuite) TestWeightedSelector() {
+	for i := range 60 {
+		s.rings[0].Push(i)
+		s.rings[1].Push(i)
+		s.rings[2].Push(i)
+	}
+	selector := NewSelector(s.rings, WithWeights(3, 2, 1))
+
+	counts := make([]int, 3)
+	for range 60 {
+		idx, _, err := selector.Pull(context.Background())
+		s.NoError(err)
+		counts[idx]++
+	}
+	s.Equal([]int{30, 20, 10}, counts)
+
+	for s.rings[0].Size() > 0 {
+		s.rings[0].TryPull()
+	}
+	for range 29 {
+		idx, _, err := selector.Pull(context.Background())
+		s.NoError(err)
+		s.NotEqual(0, idx)
+	}
+}
+
+func (s *SelectSuite) TestConcurrentPullAny() {
+	const perRing = 1000
+	wg := &sync.WaitGroup{}
+	for _, ring := range s.rings {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perRing {
+				ring.Push(i)
+			}
+		}()
+	}
+
+	results := make(chan int, 3*perRing)
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 3 * perRing / 2 {
+				idx, _, err := PullAny(context.Background(), s.rings...)
+				s.NoError(err)
+				results <- idx
+			}
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	counts := make([]int, 3)
+	for idx := range results {
+		counts[idx]++
+	}
+	s.Equal([]int{perRing, perRing, perRing}, counts)
+}
+
+func (s *SelectSuite) TestWeightedSelectorIdleRing() {
+	selector := NewSelector(s.rings[:2], WithWeights(1, 1))
+	for i := range 1000 {
+		s.rings[0].Push(i)
+	}
+	for range 1000 {
+		idx, _, err := selector.Pull(context.Background())
+		s.NoError(err)
+		s.Equal(0, idx)
+	}
+
+	// the idle ring comes back without credit and shares the pulls
+	for i := range 20 {
+		s.rings[0].Push(i)
+		s.rings[1].Push(i)
+	}
+	counts := make([]int, 2)
+	for range 20 {
+		idx, _, err := selector.Pull(context.Background())
+		s.NoError(err)
+		counts[idx]++
+	}
+	s.Equal([]int{10, 10}, counts)
+}
+
+func TestSelectSuite(t *testing.T) {
+	suite.Run(t, new(SelectSuite))
+}
@@ -0,0 +1,153 @@
+package rubberring
+
+import (
+	"context"
+	"hash/maphash"
+	"io"
+	"iter"
+	"sync/atomic"
+)
+
+type ShardedRingStat struct {
+	// Total sums the counters of all shards, positions are not meaningful for it
+	Total  RubberRingStat
+	Shards []RubberRingStat
+}
+
+// ShardedRing spreads elements over several SyncRubberRings, so producers and consumers
+// working with different shards do not contend. The order is kept only inside a shard.
+type ShardedRing[V any] struct {
+	shards   []*SyncRubberRing[V]
+	next     atomic.Uint64
+	seed     maphash.Seed
+	notifier *notifier
+}
+
+func NewShardedRing[V any](shards int, options ...applyConfigFunc) *ShardedRing[V] {
+	if shards < 1 {
+		shards = 1
+	}
+	r := &ShardedRing[V]{
+		shards:   make([]*SyncRubberRing[V], shards),
+		seed:     maphash.MakeSeed(),
+		notifier: newNotifier(),
+	}
+	for i := range r.shards {
+		r.shards[i] = NewSyncRubberRing[V](options...)
+	}
+	return r
+}
+
+func (r *ShardedRing[V]) Shards() int {
+	return len(r.shards)
+}
+
+func (r *ShardedRing[V]) Size() int {
+	size := 0
+	for _, shard := range r.shards {
+		size += shard.Size()
+	}
+	return size
+}
+
+func (r *ShardedRing[V]) Capacity() int {
+	capacity := 0
+	for _, shard := range r.shards {
+		capacity += shard.Capacity()
+	}
+	return capacity
+}
+
+func (r *ShardedRing[V]) Stat() ShardedRingStat {
+	stat := ShardedRingStat{Shards: make([]RubberRingStat, len(r.shards))}
+	for i, shard := range r.shards {
+		shardStat := shard.Stat()
+		stat.Shards[i] = shardStat
+		stat.Total.Size += shardStat.Size
+		stat.Total.Capacity += shardStat.Capacity
+		stat.Total.ActiveChanks += shardStat.ActiveChanks
+		stat.Total.ActiveCapacity += shardStat.ActiveCapacity
+		stat.Total.PassiveChanks += shardStat.PassiveChanks
+		stat.Total.PassiveCapacity += shardStat.PassiveCapacity
+		stat.Total.ActiveChanksSize = append(stat.Total.ActiveChanksSize, shardStat.ActiveChanksSize...)
+	}
+	return stat
+}
+
+// Push puts the element to the shards in round-robin order
+func (r *ShardedRing[V]) Push(value V) {
+	r.PushHint(int(r.next.Add(1)-1), value)
+}
+
+// PushHint puts the element to the shard chosen by hint (for example a worker number),
+// elements with the same hint keep their order
+func (r *ShardedRing[V]) PushHint(hint int, value V) {
+	shard := hint % len(r.shards)
+	if shard < 0 {
+		shard += len(r.shards)
+	}
+	r.shards[shard].Push(value)
+	r.notifier.notify()
+}
+
+// PushHash puts the element to the shard chosen by hash, elements with the same hash keep their order
+func (r *ShardedRing[V]) PushHash(hash uint64, value V) {
+	r.shards[hash%uint64(len(r.shards))].Push(value)
+	r.notifier.notify()
+}
+
+// PushKey puts the element to the shard chosen by key, elements with the same key keep their order
+func (r *ShardedRing[V]) PushKey(key string, value V) {
+	r.PushHash(maphash.String(r.seed, key), value)
+}
+
+// TryPull takes an element from the home shard or steals it from the others,
+// it returns io.EOF if all shards are empty
+func (r *ShardedRing[V]) TryPull(home int) (V, error) {
+	home %= len(r.shards)
+	if home < 0 {
+		home += len(r.shards)
+	}
+	for i := range r.shards {
+		v, err := r.shards[(home+i)%len(r.shards)].TryPull()
+		if err == nil {
+			return v, nil
+		}
+	}
+	var v V
+	return v, io.EOF
+}
+
+// Pull works like TryPull, but waits for an element if all shards are empty
+func (r *ShardedRing[V]) Pull(ctx context.Context, home int) (V, error) {
+	for {
+		v, err := r.TryPull(home)
+		if err == nil {
+			return v, nil
+		}
+		if err := r.notifier.wait(ctx, func() bool { return r.Size() > 0 }); err != nil {
+			return v, err
+		}
+		v, err = r.TryPull(home)
+		if err == nil {
+			if r.Size() > 0 {
+				r.notifier.notify()
+			}
+			return v, nil
+		}
+	}
+}
+
+func (r *ShardedRing[V]) Elements(ctx context.Context, home int) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for {
+			v, err := r.Pull(ctx, home)
+			if err != nil {
+				return
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
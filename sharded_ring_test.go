@@ -0,0 +1,131 @@
+package rubberring
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/goleak"
+)
+
+type ShardedRingSuite struct {
+	suite.Suite
+	ring *ShardedRing[int]
+}
+
+func (s *ShardedRingSuite) SetupTest() {
+	s.ring = NewShardedRing[int](
+		3,
+		WithStartChankSize(2),
+		WithStartChankCount(1),
+	)
+}
+
+func (s *ShardedRingSuite) TearDownTest() {
+	s.NoError(goleak.Find())
+	s.ring = nil
+}
+
+func (s *ShardedRingSuite) TestRoundRobin() {
+	for i := range 6 {
+		s.ring.Push(i)
+	}
+	stat := s.ring.Stat()
+	s.Equal(6, stat.Total.Size)
+	for _, shard := range stat.Shards {
+		s.Equal(2, shard.Size)
+	}
+	s.Equal([]int{0, 3}, s.ring.shards[0].ToSlice())
+}
+
+func (s *ShardedRingSuite) TestPushKeyKeepsOrder() {
+	for i := range 10 {
+		s.ring.PushKey("user-1", i)
+		s.ring.PushHint(-1, 100+i)
+	}
+
+	var user, hinted []int
+	for v := range s.ring.Elements(context.Background(), 0) {
+		if v < 100 {
+			user = append(user, v)
+		} else {
+			hinted = append(hinted, v)
+		}
+		if len(user)+len(hinted) == 20 {
+			break
+		}
+	}
+	s.Equal([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, user)
+	s.Equal([]int{100, 101, 102, 103, 104, 105, 106, 107, 108, 109}, hinted)
+}
+
+func (s *ShardedRingSuite) TestStealing() {
+	s.ring.PushHint(2, 42)
+
+	_, err := s.ring.shards[0].TryPull()
+	s.Equal(io.EOF, err)
+	v, err := s.ring.TryPull(0)
+	s.NoError(err)
+	s.Equal(42, v)
+
+	_, err = s.ring.TryPull(0)
+	s.Equal(io.EOF, err)
+}
+
+func (s *ShardedRingSuite) TestPullWaits() {
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		s.ring.PushHint(1, 7)
+	}()
+	v, err := s.ring.Pull(context.Background(), 0)
+	s.NoError(err)
+	s.Equal(7, v)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = s.ring.Pull(ctx, 0)
+	s.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func (s *ShardedRingSuite) TestConcurrent() {
+	const workers = 6
+	const perWorker = 2000
+	ctx := context.Background()
+
+	wg := &sync.WaitGroup{}
+	results := make(chan int, workers*perWorker)
+	for w := range workers {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := range perWorker {
+				s.ring.PushHint(w, w*perWorker+i)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range perWorker {
+				v, err := s.ring.Pull(ctx, w)
+				s.NoError(err)
+				results <- v
+			}
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	seen := make(map[int]bool)
+	for v := range results {
+		s.False(seen[v])
+		seen[v] = true
+	}
+	s.Len(seen, workers*perWorker)
+	s.Equal(0, s.ring.Size())
+}
+
+func TestShardedRingSuite(t *testing.T) {
+	suite.Run(t, new(ShardedRingSuite))
+}
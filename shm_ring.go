@@ -0,0 +1,263 @@
+//go:build linux
+
+package rubberring
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// shared memory layout, all offsets are bytes from the start of the file:
+//
+//	0   magic uint32 | version uint32 | elemSize | chankSize | chankCount | firstChank
+//	64  startChank | startPosition | pulled   (owned by the consumer)
+//	128 endChank | endPosition | pushed       (owned by the producer)
+//	192 chunks: nextChank offset | padding up to 64 | chankSize elements
+//
+// chunks are linked into a cycle by offsets, so the ring has a fixed capacity
+const (
+	shmMagic         = 0x47534252 // "RBSG"
+	shmVersion       = 1
+	shmHeaderSize    = 192
+	shmChankHeader   = 64
+	shmLineSize      = 64
+	shmOpenAttempts  = 100
+	shmOpenRetryWait = 10 * time.Millisecond
+
+	shmMagicOffset         = 0
+	shmVersionOffset       = 4
+	shmElemSizeOffset      = 8
+	shmChankSizeOffset     = 16
+	shmChankCountOffset    = 24
+	shmFirstChankOffset    = 32
+	shmStartChankOffset    = 64
+	shmStartPositionOffset = 72
+	shmPulledOffset        = 80
+	shmEndChankOffset      = 128
+	shmEndPositionOffset   = 136
+	shmPushedOffset        = 144
+)
+
+var (
+	ErrRingFull            = errors.New("rubberring: ring is full")
+	ErrUnsupportedType     = errors.New("rubberring: type is not fixed-size and pointer-free")
+	ErrIncompatibleShmRing = errors.New("rubberring: shared memory ring has incompatible layout")
+)
+
+// ShmRing is a fixed capacity ring in a memory mapped file, it allows one producer and
+// one consumer (possibly in different processes) to exchange elements without copying
+// them through the kernel. V must be fixed-size and pointer-free.
+type ShmRing[V any] struct {
+	file       *os.File
+	mem        []byte
+	elemSize   uint64
+	chankSize  uint64
+	chankCount uint64
+}
+
+// OpenShmRing maps the ring stored in path (for example under /dev/shm), the file is created
+// with WithStartChankSize and WithStartChankCount geometry if it does not exist
+func OpenShmRing[V any](path string, options ...applyConfigFunc) (*ShmRing[V], error) {
+	var v V
+	if !isPointerFree(reflect.TypeFor[V]()) || unsafe.Sizeof(v) == 0 {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedType, v)
+	}
+	config := defaultConfig
+	for _, option := range options {
+		option(&config)
+	}
+
+	r := &ShmRing[V]{elemSize: uint64(unsafe.Sizeof(v))}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+	switch {
+	case err == nil:
+		err = r.create(file, uint64(config.startChankSize), uint64(config.startChankCount))
+	case errors.Is(err, os.ErrExist):
+		file, err = os.OpenFile(path, os.O_RDWR, 0)
+		if err == nil {
+			err = r.open(file)
+		}
+	}
+	if err != nil {
+		if file != nil {
+			file.Close()
+		}
+		return nil, err
+	}
+	r.file = file
+	return r, nil
+}
+
+func (r *ShmRing[V]) create(file *os.File, chankSize, chankCount uint64) error {
+	r.chankSize = chankSize
+	r.chankCount = chankCount
+	size := shmHeaderSize + chankCount*r.chankStride()
+	if err := file.Truncate(int64(size)); err != nil {
+		return err
+	}
+	if err := r.mmap(file, size); err != nil {
+		return err
+	}
+
+	r.u64(shmElemSizeOffset).Store(r.elemSize)
+	r.u64(shmChankSizeOffset).Store(chankSize)
+	r.u64(shmChankCountOffset).Store(chankCount)
+	r.u64(shmFirstChankOffset).Store(shmHeaderSize)
+	for i := range chankCount {
+		next := shmHeaderSize + (i+1)%chankCount*r.chankStride()
+		r.u64(uintptr(shmHeaderSize + i*r.chankStride())).Store(next)
+	}
+	r.u64(shmStartChankOffset).Store(shmHeaderSize)
+	r.u64(shmEndChankOffset).Store(shmHeaderSize)
+	r.u32(shmVersionOffset).Store(shmVersion)
+	// the magic is written last, so an opener never sees a half initialized ring
+	r.u32(shmMagicOffset).Store(shmMagic)
+	return nil
+}
+
+func (r *ShmRing[V]) open(file *os.File) error {
+	for attempt := 0; ; attempt++ {
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		if info.Size() >= shmHeaderSize {
+			if r.mem == nil {
+				if err := r.mmap(file, uint64(info.Size())); err != nil {
+					return err
+				}
+			}
+			if r.u32(shmMagicOffset).Load() == shmMagic {
+				break
+			}
+		}
+		if attempt >= shmOpenAttempts {
+			return fmt.Errorf("%w: not initialized", ErrIncompatibleShmRing)
+		}
+		time.Sleep(shmOpenRetryWait)
+	}
+
+	if r.u32(shmVersionOffset).Load() != shmVersion ||
+		r.u64(shmElemSizeOffset).Load() != r.elemSize {
+		return ErrIncompatibleShmRing
+	}
+	r.chankSize = r.u64(shmChankSizeOffset).Load()
+	r.chankCount = r.u64(shmChankCountOffset).Load()
+	if uint64(len(r.mem)) < shmHeaderSize+r.chankCount*r.chankStride() {
+		return ErrIncompatibleShmRing
+	}
+	return nil
+}
+
+func (r *ShmRing[V]) mmap(file *os.File, size uint64) error {
+	mem, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return err
+	}
+	r.mem = mem
+	return nil
+}
+
+func (r *ShmRing[V]) chankStride() uint64 {
+	data := (r.chankSize*r.elemSize + shmLineSize - 1) / shmLineSize * shmLineSize
+	return shmChankHeader + data
+}
+
+func (r *ShmRing[V]) u64(offset uintptr) *atomic.Uint64 {
+	return (*atomic.Uint64)(unsafe.Pointer(&r.mem[offset]))
+}
+
+func (r *ShmRing[V]) u32(offset uintptr) *atomic.Uint32 {
+	return (*atomic.Uint32)(unsafe.Pointer(&r.mem[offset]))
+}
+
+func (r *ShmRing[V]) element(chank, position uint64) *V {
+	return (*V)(unsafe.Pointer(&r.mem[chank+shmChankHeader+position*r.elemSize]))
+}
+
+func (r *ShmRing[V]) Size() int {
+	// pulled never passes pushed, so it is loaded first and the difference does not wrap around
+	pulled := r.u64(shmPulledOffset).Load()
+	return int(r.u64(shmPushedOffset).Load() - pulled)
+}
+
+func (r *ShmRing[V]) Capacity() int {
+	return int(r.chankSize * r.chankCount)
+}
+
+// Push must be called by a single producer, it returns ErrRingFull if there is no free space
+func (r *ShmRing[V]) Push(el V) error {
+	pushed := r.u64(shmPushedOffset)
+	if pushed.Load()-r.u64(shmPulledOffset).Load() >= uint64(r.Capacity()) {
+		return ErrRingFull
+	}
+	endChank := r.u64(shmEndChankOffset).Load()
+	endPosition := r.u64(shmEndPositionOffset).Load()
+	*r.element(endChank, endPosition) = el
+	endPosition++
+	if endPosition >= r.chankSize {
+		r.u64(shmEndChankOffset).Store(r.u64(uintptr(endChank)).Load())
+		endPosition = 0
+	}
+	r.u64(shmEndPositionOffset).Store(endPosition)
+	pushed.Add(1)
+	return nil
+}
+
+// Pull must be called by a single consumer, it returns io.EOF if the ring is empty
+func (r *ShmRing[V]) Pull() (V, error) {
+	var el V
+	pulled := r.u64(shmPulledOffset)
+	if pulled.Load() == r.u64(shmPushedOffset).Load() {
+		return el, io.EOF
+	}
+	startChank := r.u64(shmStartChankOffset).Load()
+	startPosition := r.u64(shmStartPositionOffset).Load()
+	el = *r.element(startChank, startPosition)
+	startPosition++
+	if startPosition >= r.chankSize {
+		r.u64(shmStartChankOffset).Store(r.u64(uintptr(startChank)).Load())
+		startPosition = 0
+	}
+	r.u64(shmStartPositionOffset).Store(startPosition)
+	pulled.Add(1)
+	return el, nil
+}
+
+// Close unmaps the ring, the file itself is left in place
+func (r *ShmRing[V]) Close() error {
+	err := syscall.Munmap(r.mem)
+	r.mem = nil
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func isPointerFree(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Array:
+		return isPointerFree(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if !isPointerFree(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
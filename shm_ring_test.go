@@ -0,0 +1,138 @@
+//go:build linux
+
+package rubberring
+
+import (
+	"io"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type shmTestItem struct {
+	ID    uint64
+	Value float64
+	Tag   [4]byte
+}
+
+type ShmRingSuite struct {
+	suite.Suite
+	path string
+}
+
+func (s *ShmRingSuite) SetupTest() {
+	s.path = filepath.Join(s.T().TempDir(), "ring")
+}
+
+func (s *ShmRingSuite) open() *ShmRing[shmTestItem] {
+	ring, err := OpenShmRing[shmTestItem](
+		s.path,
+		WithStartChankSize(3),
+		WithStartChankCount(2),
+	)
+	s.Require().NoError(err)
+	return ring
+}
+
+func (s *ShmRingSuite) TestPushPull() {
+	ring := s.open()
+	defer ring.Close()
+
+	_, err := ring.Pull()
+	s.Equal(io.EOF, err)
+	s.Equal(6, ring.Capacity())
+
+	for round := range 5 {
+		for i := range 4 {
+			s.NoError(ring.Push(shmTestItem{ID: uint64(round*4 + i), Tag: [4]byte{'t'}}))
+		}
+		s.Equal(4, ring.Size())
+		for i := range 4 {
+			item, err := ring.Pull()
+			s.NoError(err)
+			s.Equal(uint64(round*4+i), item.ID)
+			s.Equal(byte('t'), item.Tag[0])
+		}
+	}
+}
+
+func (s *ShmRingSuite) TestFull() {
+	ring := s.open()
+	defer ring.Close()
+
+	for i := range 6 {
+		s.NoError(ring.Push(shmTestItem{ID: uint64(i)}))
+	}
+	s.ErrorIs(ring.Push(shmTestItem{}), ErrRingFull)
+	_, err := ring.Pull()
+	s.NoError(err)
+	s.NoError(ring.Push(shmTestItem{ID: 6}))
+}
+
+func (s *ShmRingSuite) TestSharedBetweenMappings() {
+	producer := s.open()
+	defer producer.Close()
+	consumer, err := OpenShmRing[shmTestItem](s.path)
+	s.Require().NoError(err)
+	defer consumer.Close()
+	s.Equal(6, consumer.Capacity())
+
+	const count = 10000
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < count; {
+			if producer.Push(shmTestItem{ID: uint64(i), Value: float64(i) / 2}) != nil {
+				runtime.Gosched()
+				continue
+			}
+			i++
+		}
+	}()
+	for i := 0; i < count; {
+		item, err := consumer.Pull()
+		if err != nil {
+			runtime.Gosched()
+			continue
+		}
+		s.Require().Equal(uint64(i), item.ID)
+		s.Require().Equal(float64(i)/2, item.Value)
+		i++
+	}
+	<-done
+}
+
+func (s *ShmRingSuite) TestReopen() {
+	ring := s.open()
+	s.NoError(ring.Push(shmTestItem{ID: 1}))
+	s.NoError(ring.Push(shmTestItem{ID: 2}))
+	s.NoError(ring.Close())
+
+	ring = s.open()
+	defer ring.Close()
+	s.Equal(2, ring.Size())
+	item, err := ring.Pull()
+	s.NoError(err)
+	s.Equal(uint64(1), item.ID)
+}
+
+func (s *ShmRingSuite) TestIncompatible() {
+	ring := s.open()
+	s.NoError(ring.Close())
+
+	_, err := OpenShmRing[int32](s.path)
+	s.ErrorIs(err, ErrIncompatibleShmRing)
+
+	_, err = OpenShmRing[*int](filepath.Join(s.T().TempDir(), "pointer"))
+	s.ErrorIs(err, ErrUnsupportedType)
+	_, err = OpenShmRing[struct{ Name string }](filepath.Join(s.T().TempDir(), "string"))
+	s.ErrorIs(err, ErrUnsupportedType)
+	_, err = OpenShmRing[any](filepath.Join(s.T().TempDir(), "any"))
+	s.ErrorIs(err, ErrUnsupportedType)
+}
+
+func TestShmRingSuite(t *testing.T) {
+	suite.Run(t, new(ShmRingSuite))
+}
@@ -0,0 +1,38 @@
+package rubberring
+
+import (
+	"iter"
+	"slices"
+)
+
+// RingSnapshot is a read-only copy of the ring contents taken at a single moment
+type RingSnapshot[V any] struct {
+	elements []V
+	stat     RubberRingStat
+}
+
+func (s RingSnapshot[V]) Size() int {
+	return len(s.elements)
+}
+
+func (s RingSnapshot[V]) At(i int) V {
+	return s.elements[i]
+}
+
+func (s RingSnapshot[V]) Stat() RubberRingStat {
+	stat := s.stat
+	stat.ActiveChanksSize = slices.Clone(s.stat.ActiveChanksSize)
+	return stat
+}
+
+func (s RingSnapshot[V]) Elements() iter.Seq[V] {
+	return slices.Values(s.elements)
+}
+
+func (s RingSnapshot[V]) ToSlice() []V {
+	return slices.Clone(s.elements)
+}
+
+func (s RingSnapshot[V]) AppendTo(dst []V) []V {
+	return append(dst, s.elements...)
+}
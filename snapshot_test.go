@@ -0,0 +1,46 @@
+package rubberring
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RingSnapshotSuite struct {
+	suite.Suite
+	snapshot RingSnapshot[int]
+}
+
+func (s *RingSnapshotSuite) SetupTest() {
+	ring := NewSyncRubberRing[int](
+		WithStartChankSize(2),
+		WithStartChankCount(2),
+	)
+	for i := range 3 {
+		ring.Push(i)
+	}
+	s.snapshot = ring.Snapshot()
+}
+
+func (s *RingSnapshotSuite) TestAccess() {
+	s.Equal(3, s.snapshot.Size())
+	s.Equal(1, s.snapshot.At(1))
+	s.Equal([]int{0, 1, 2}, slices.Collect(s.snapshot.Elements()))
+	s.Equal([]int{9, 0, 1, 2}, s.snapshot.AppendTo([]int{9}))
+}
+
+func (s *RingSnapshotSuite) TestImmutable() {
+	elements := s.snapshot.ToSlice()
+	elements[0] = 100
+
+	stat := s.snapshot.Stat()
+	stat.ActiveChanksSize[0] = 100
+
+	s.Equal(0, s.snapshot.At(0))
+	s.Equal([]int{2, 2}, s.snapshot.Stat().ActiveChanksSize)
+}
+
+func TestRingSnapshotSuite(t *testing.T) {
+	suite.Run(t, new(RingSnapshotSuite))
+}
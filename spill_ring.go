@@ -0,0 +1,241 @@
+package rubberring
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+type SpillRingStat struct {
+	Size             int
+	Capacity         int
+	ResidentCapacity int
+	SpilledChanks    int
+	SpilledCapacity  int
+}
+
+// SpillRing is a thread-unsafe ring that keeps at most memoryLimit elements of capacity in memory
+// (plus the chunk being read), chunks beyond the limit are written to segment files.
+// A chunk is either resident (data is set) or spilled (segment is set).
+type SpillRing[V any] struct {
+	startChank       *chank[V]
+	startPosition    int
+	endChank         *chank[V]
+	endPosition      int
+	freeChanks       chan *chank[V]
+	size             int
+	capacity         int
+	residentCapacity int
+	spilledChanks    int
+	spilledCapacity  int
+	memoryLimit      int
+	segmentNo        uint64
+	dir              string
+	codec            Codec[V]
+	config           config
+}
+
+func NewSpillRing[V any](
+	dir string,
+	codec Codec[V],
+	memoryLimit int,
+	options ...applyConfigFunc,
+) (*SpillRing[V], error) {
+	config := defaultConfig
+	for _, option := range options {
+		option(&config)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	ringDir, err := os.MkdirTemp(dir, "spill-")
+	if err != nil {
+		return nil, err
+	}
+
+	r := &SpillRing[V]{
+		freeChanks:  make(chan *chank[V], config.pasiveChankBufferSize),
+		memoryLimit: memoryLimit,
+		dir:         ringDir,
+		codec:       codec,
+		config:      config,
+	}
+	r.startChank = createNewChankChain[V](config.startChankSize, config.startChankCount)
+	r.endChank = r.startChank
+	r.capacity = max(1, config.startChankCount) * len(r.startChank.data)
+	r.residentCapacity = r.capacity
+	return r, nil
+}
+
+func (r *SpillRing[V]) Size() int {
+	return r.size
+}
+
+func (r *SpillRing[V]) Capacity() int {
+	return r.capacity
+}
+
+func (r *SpillRing[V]) Stat() SpillRingStat {
+	return SpillRingStat{
+		Size:             r.size,
+		Capacity:         r.capacity,
+		ResidentCapacity: r.residentCapacity,
+		SpilledChanks:    r.spilledChanks,
+		SpilledCapacity:  r.spilledCapacity,
+	}
+}
+
+// Push returns the error of spilling the filled chunk, the element is not pushed then
+func (r *SpillRing[V]) Push(el V) error {
+	r.endChank.data[r.endPosition] = el
+	r.endPosition++
+	r.size++
+	if r.endPosition < len(r.endChank.data) {
+		return nil
+	}
+
+	newEndChank := r.endChank.nextChank
+	if newEndChank == nil {
+		var err error
+		newEndChank, err = r.nextEndChank()
+		if err != nil {
+			// the chunk stays resident with a free slot, so the next Push tries to spill it again
+			var zero V
+			r.endPosition--
+			r.endChank.data[r.endPosition] = zero
+			r.size--
+			return err
+		}
+	}
+	r.endChank.nextChank = newEndChank
+	r.endChank = newEndChank
+	r.endPosition = 0
+	return nil
+}
+
+func (r *SpillRing[V]) nextEndChank() (*chank[V], error) {
+	select {
+	case chk := <-r.freeChanks:
+		return chk, nil
+	default:
+	}
+
+	newChankSize, newChankCount := r.config.growStrategy(r.capacity)
+	if r.endChank == r.startChank ||
+		r.residentCapacity+newChankSize*max(newChankCount, 1) <= r.memoryLimit {
+		chanks := createNewChankChain[V](newChankSize, newChankCount)
+		capacity := max(1, newChankCount) * len(chanks.data)
+		r.capacity += capacity
+		r.residentCapacity += capacity
+		return chanks, nil
+	}
+
+	// the filled chunk is read last, so it goes to disk and its memory is reused for the next one
+	full := r.endChank
+	if err := r.spill(full); err != nil {
+		return nil, err
+	}
+	data := full.data
+	full.data = nil
+	clear(data)
+	r.capacity += len(data)
+	return &chank[V]{data: data}, nil
+}
+
+func (r *SpillRing[V]) spill(chk *chank[V]) error {
+	r.segmentNo++
+	segment := filepath.Join(r.dir, fmt.Sprintf("%016x.seg", r.segmentNo))
+	f, err := os.Create(segment)
+	if err != nil {
+		return err
+	}
+	_, err = writeDump(f, r.config, len(chk.data), slices.Values(chk.data), r.codec)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(segment)
+		return err
+	}
+	chk.segment = &chankSegment{path: segment, size: len(chk.data)}
+	r.spilledChanks++
+	r.spilledCapacity += len(chk.data)
+	return nil
+}
+
+func (r *SpillRing[V]) load(chk *chank[V]) error {
+	segment := chk.segment
+	data, err := os.ReadFile(segment.path)
+	if err != nil {
+		return err
+	}
+	_, elements, _, err := readDump(bytes.NewReader(data), r.codec)
+	if err != nil {
+		return err
+	}
+	if len(elements) != segment.size {
+		return fmt.Errorf("%w: segment %s has %d elements, expected %d",
+			ErrInvalidDump, segment.path, len(elements), segment.size)
+	}
+	if err := os.Remove(segment.path); err != nil {
+		return err
+	}
+	chk.data = elements
+	chk.segment = nil
+	r.residentCapacity += segment.size
+	r.spilledChanks--
+	r.spilledCapacity -= segment.size
+	return nil
+}
+
+func (r *SpillRing[V]) Pull() (V, error) {
+	var el V
+	if r.size == 0 {
+		return el, io.EOF
+	}
+	if r.startChank.data == nil {
+		if err := r.load(r.startChank); err != nil {
+			return el, err
+		}
+	}
+	el = r.startChank.data[r.startPosition]
+	r.startPosition++
+	r.size--
+	if r.startPosition >= len(r.startChank.data) {
+		chk := r.startChank
+		r.startChank = chk.nextChank
+		clear(chk.data)
+		chk.nextChank = nil
+		select {
+		case r.freeChanks <- chk:
+		default:
+			r.capacity -= len(chk.data)
+			r.residentCapacity -= len(chk.data)
+		}
+		r.startPosition = 0
+	}
+	return el, nil
+}
+
+func (r *SpillRing[V]) Elements() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for {
+			v, err := r.Pull()
+			if err != nil {
+				return
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Close removes all segment files of the ring, the ring must not be used afterwards
+func (r *SpillRing[V]) Close() error {
+	return os.RemoveAll(r.dir)
+}
@@ -0,0 +1,153 @@
+package rubberring
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SpillRingSuite struct {
+	suite.Suite
+	dir  string
+	ring *SpillRing[int64]
+}
+
+func (s *SpillRingSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	ring, err := NewSpillRing(
+		s.dir,
+		NumericCodec[int64](),
+		4,
+		WithStartChankSize(2),
+		WithStartChankCount(1),
+		WithGrowStrategy(func(int) (int, int) { return 2, 1 }),
+		WithPassiveChankBufferSize(1),
+	)
+	s.Require().NoError(err)
+	s.ring = ring
+}
+
+func (s *SpillRingSuite) TearDownTest() {
+	s.NoError(s.ring.Close())
+	s.ring = nil
+}
+
+func (s *SpillRingSuite) segments() []string {
+	segments, err := filepath.Glob(filepath.Join(s.dir, "*", "*.seg"))
+	s.Require().NoError(err)
+	return segments
+}
+
+func (s *SpillRingSuite) TestPushPull() {
+	_, err := s.ring.Pull()
+	s.Equal(io.EOF, err)
+
+	for i := range 5 {
+		s.NoError(s.ring.Push(int64(i)))
+	}
+	for i := range 5 {
+		v, err := s.ring.Pull()
+		s.NoError(err)
+		s.Equal(int64(i), v)
+	}
+	s.Equal(0, s.ring.Size())
+	s.Empty(s.segments())
+}
+
+func (s *SpillRingSuite) TestSpillOverMemoryLimit() {
+	for i := range 20 {
+		s.NoError(s.ring.Push(int64(i)))
+	}
+
+	stat := s.ring.Stat()
+	s.Equal(20, stat.Size)
+	s.LessOrEqual(stat.ResidentCapacity, 4)
+	s.Equal(stat.SpilledChanks, len(s.segments()))
+	s.Greater(stat.SpilledChanks, 0)
+	s.GreaterOrEqual(stat.Capacity, 20)
+
+	for i := range 20 {
+		v, err := s.ring.Pull()
+		s.NoError(err)
+		s.Equal(int64(i), v)
+		s.LessOrEqual(s.ring.Stat().ResidentCapacity, 6)
+	}
+	s.Empty(s.segments())
+	s.Equal(0, s.ring.Stat().SpilledCapacity)
+}
+
+func (s *SpillRingSuite) TestInterleaved() {
+	next, expected := int64(0), int64(0)
+	for round := range 10 {
+		for range round + 3 {
+			s.NoError(s.ring.Push(next))
+			next++
+		}
+		for range round + 1 {
+			v, err := s.ring.Pull()
+			s.NoError(err)
+			s.Equal(expected, v)
+			expected++
+		}
+	}
+	for v := range s.ring.Elements() {
+		s.Equal(expected, v)
+		expected++
+	}
+	s.Equal(next, expected)
+}
+
+func (s *SpillRingSuite) TestCorruptedSegment() {
+	for i := range 10 {
+		s.NoError(s.ring.Push(int64(i)))
+	}
+	segments := s.segments()
+	s.Require().NotEmpty(segments)
+	s.NoError(os.WriteFile(segments[0], []byte("junk segment"), 0o644))
+
+	var err error
+	for range 10 {
+		if _, err = s.ring.Pull(); err != nil {
+			break
+		}
+	}
+	s.ErrorIs(err, ErrInvalidDump)
+}
+
+func (s *SpillRingSuite) TestClose() {
+	for i := range 10 {
+		s.NoError(s.ring.Push(int64(i)))
+	}
+	s.NotEmpty(s.segments())
+	s.NoError(s.ring.Close())
+	s.Empty(s.segments())
+}
+
+func (s *SpillRingSuite) TestSpillError() {
+	for i := range 3 {
+		s.NoError(s.ring.Push(int64(i)))
+	}
+	// the fourth element fills the second chunk, which has to be spilled
+	s.Require().NoError(os.RemoveAll(s.ring.dir))
+	for range 2 {
+		s.Error(s.ring.Push(3))
+		s.Equal(3, s.ring.Size())
+	}
+
+	s.Require().NoError(os.Mkdir(s.ring.dir, 0o755))
+	s.NoError(s.ring.Push(3))
+	s.NoError(s.ring.Push(4))
+	s.Equal(1, s.ring.Stat().SpilledChanks)
+	for i := range 5 {
+		v, err := s.ring.Pull()
+		s.NoError(err)
+		s.Equal(int64(i), v)
+	}
+}
+
+func TestSpillRingSuite(t *testing.T) {
+	suite.Run(t, new(SpillRingSuite))
+}
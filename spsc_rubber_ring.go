@@ -0,0 +1,157 @@
+package rubberring
+
+import (
+	"context"
+	"io"
+	"iter"
+	"sync/atomic"
+)
+
+type spscChank[V any] struct {
+	data      []V
+	nextChank atomic.Pointer[spscChank[V]]
+}
+
+// SPSCRubberRing is a lock-free ring for exactly one producer goroutine and one consumer goroutine.
+// The producer links new chunks before publishing the element that filled the current one,
+// so the consumer always finds the next chunk when it reaches the end of a chunk.
+type SPSCRubberRing[V any] struct {
+	startChank    *spscChank[V]
+	startPosition int
+	_             cacheLinePad
+	endChank      *spscChank[V]
+	endPosition   int
+	_             cacheLinePad
+	pushed        atomic.Uint64
+	_             cacheLinePad
+	pulled        atomic.Uint64
+	_             cacheLinePad
+	capacity      atomic.Int64
+	freeChanks    chan *spscChank[V]
+	notifier      *notifier
+	config        config
+}
+
+func NewSPSCRubberRing[V any](options ...applyConfigFunc) *SPSCRubberRing[V] {
+	config := defaultConfig
+	for _, option := range options {
+		option(&config)
+	}
+
+	rr := &SPSCRubberRing[V]{
+		config:     config,
+		freeChanks: make(chan *spscChank[V], config.pasiveChankBufferSize),
+		notifier:   newNotifier(),
+	}
+	chanks, capacity := createNewSPSCChankChain[V](
+		config.startChankSize,
+		config.startChankCount,
+	)
+	rr.startChank = chanks
+	rr.endChank = chanks
+	rr.capacity.Store(int64(capacity))
+	return rr
+}
+
+func (r *SPSCRubberRing[V]) Size() int {
+	// pulled never passes pushed, so it is loaded first and the size is not negative
+	pulled := r.pulled.Load()
+	return int(r.pushed.Load() - pulled)
+}
+
+func (r *SPSCRubberRing[V]) Capacity() int {
+	return int(r.capacity.Load())
+}
+
+// Push must only be called from the producer goroutine
+func (r *SPSCRubberRing[V]) Push(el V) {
+	r.endChank.data[r.endPosition] = el
+	r.endPosition++
+	if r.endPosition >= len(r.endChank.data) {
+		newEndChank := r.endChank.nextChank.Load()
+		if newEndChank == nil {
+			select {
+			case newEndChank = <-r.freeChanks:
+			default:
+				newChankSize, newChankCount := r.config.growStrategy(r.Capacity())
+				var capacity int
+				newEndChank, capacity = createNewSPSCChankChain[V](newChankSize, newChankCount)
+				r.capacity.Add(int64(capacity))
+			}
+			r.endChank.nextChank.Store(newEndChank)
+		}
+		r.endChank = newEndChank
+		r.endPosition = 0
+	}
+	r.pushed.Add(1)
+	r.notifier.notify()
+}
+
+// TryPull must only be called from the consumer goroutine, it returns io.EOF if the ring is empty
+func (r *SPSCRubberRing[V]) TryPull() (V, error) {
+	var el V
+	if r.pulled.Load() == r.pushed.Load() {
+		return el, io.EOF
+	}
+	el = r.startChank.data[r.startPosition]
+	r.startPosition++
+	if r.startPosition >= len(r.startChank.data) {
+		oldStartChank := r.startChank
+		r.startChank = oldStartChank.nextChank.Load()
+		r.startPosition = 0
+		oldStartChank.nextChank.Store(nil)
+		select {
+		case r.freeChanks <- oldStartChank:
+		default:
+			r.capacity.Add(-int64(len(oldStartChank.data)))
+		}
+	}
+	r.pulled.Add(1)
+	return el, nil
+}
+
+// Pull must only be called from the consumer goroutine, it waits for an element if the ring is empty
+func (r *SPSCRubberRing[V]) Pull(ctx context.Context) (V, error) {
+	for {
+		v, err := r.TryPull()
+		if err == nil {
+			return v, nil
+		}
+		if err := r.notifier.wait(ctx, func() bool { return r.Size() > 0 }); err != nil {
+			return v, err
+		}
+	}
+}
+
+func (r *SPSCRubberRing[V]) Elements(ctx context.Context) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for {
+			v, err := r.Pull(ctx)
+			if err != nil {
+				return
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func createNewSPSCChankChain[V any](
+	chankSize int,
+	chankCount int,
+) (*spscChank[V], int) {
+	if chankCount < 1 {
+		chankCount = 1
+	}
+	if chankSize < 1 {
+		chankSize = 256
+	}
+	var chk *spscChank[V]
+	for i := 0; i < chankCount; i++ {
+		newChank := &spscChank[V]{data: make([]V, chankSize)}
+		newChank.nextChank.Store(chk)
+		chk = newChank
+	}
+	return chk, chankSize * chankCount
+}
@@ -0,0 +1,151 @@
+package rubberring
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/goleak"
+)
+
+type SPSCRubberRingSuite struct {
+	suite.Suite
+	ring *SPSCRubberRing[int]
+}
+
+func (s *SPSCRubberRingSuite) SetupTest() {
+	s.ring = NewSPSCRubberRing[int](
+		WithStartChankSize(3),
+		WithStartChankCount(2),
+		WithGrowStrategy(func(int) (int, int) { return 3, 1 }),
+		WithPassiveChankBufferSize(1),
+	)
+}
+
+func (s *SPSCRubberRingSuite) TearDownTest() {
+	s.NoError(goleak.Find())
+	s.ring = nil
+}
+
+func (s *SPSCRubberRingSuite) TestPushPull() {
+	_, err := s.ring.TryPull()
+	s.Equal(io.EOF, err)
+
+	for i := range 10 {
+		s.ring.Push(i)
+	}
+	s.Equal(10, s.ring.Size())
+	s.Equal(12, s.ring.Capacity())
+
+	for i := range 10 {
+		v, err := s.ring.TryPull()
+		s.NoError(err)
+		s.Equal(i, v)
+	}
+	s.Equal(0, s.ring.Size())
+	// one drained chunk is kept in the passive buffer, the others are released
+	s.Equal(6, s.ring.Capacity())
+}
+
+func (s *SPSCRubberRingSuite) TestConcurrent() {
+	const count = 100000
+	go func() {
+		for i := range count {
+			s.ring.Push(i)
+		}
+	}()
+
+	ctx := context.Background()
+	for i := range count {
+		v, err := s.ring.Pull(ctx)
+		s.Require().NoError(err)
+		s.Require().Equal(i, v)
+	}
+	s.Equal(0, s.ring.Size())
+}
+
+func (s *SPSCRubberRingSuite) TestPullWaits() {
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		s.ring.Push(42)
+	}()
+
+	v, err := s.ring.Pull(context.Background())
+	s.NoError(err)
+	s.Equal(42, v)
+}
+
+func (s *SPSCRubberRingSuite) TestContextCancellation() {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := s.ring.Pull(ctx)
+	s.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func (s *SPSCRubberRingSuite) TestElements() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for i := range 5 {
+		s.ring.Push(i)
+	}
+
+	var result []int
+	for v := range s.ring.Elements(ctx) {
+		result = append(result, v)
+		if len(result) == 5 {
+			cancel()
+		}
+	}
+	s.Equal([]int{0, 1, 2, 3, 4}, result)
+}
+
+func TestSPSCRubberRingSuite(t *testing.T) {
+	suite.Run(t, new(SPSCRubberRingSuite))
+}
+
+const benchmarkBurst = 1024
+
+func BenchmarkSPSCRubberRing(b *testing.B) {
+	ring := NewSPSCRubberRing[int]()
+	ctx := context.Background()
+	go func() {
+		for i := range b.N {
+			ring.Push(i)
+		}
+	}()
+	for range b.N {
+		if _, err := ring.Pull(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSyncRubberRingSPSC(b *testing.B) {
+	ring := NewSyncRubberRing[int]()
+	ctx := context.Background()
+	go func() {
+		for i := range b.N {
+			ring.Push(i)
+		}
+	}()
+	for range b.N {
+		if _, err := ring.Pull(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkChannelSPSC(b *testing.B) {
+	ch := make(chan int, benchmarkBurst)
+	go func() {
+		for i := range b.N {
+			ch <- i
+		}
+	}()
+	for range b.N {
+		<-ch
+	}
+}
@@ -2,71 +2,186 @@ package rubberring
 
 import (
 	"context"
+	"io"
 	"iter"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	syncutils "github.com/Skrip42/syncUtils"
 )
 
+// SyncRubberRing guards the start of the chain (Pull) and the end of the chain (Push)
+// with separate locks, so one producer and one consumer do not contend.
+// The size and capacity of the inner ring are kept in atomics instead.
 type SyncRubberRing[V any] struct {
-	ring *RubberRing[V]
-	cond *syncutils.Cond
-	mu   *sync.Mutex
+	ring     *RubberRing[V]
+	cond     *syncutils.Cond
+	headMu   *sync.Mutex
+	tailMu   *sync.Mutex
+	size     atomic.Int64
+	capacity atomic.Int64
+	waiters  atomic.Int32
+	// waitMu only covers the registration of a waiter, so a producer
+	// signals without taking headMu, which a consumer holds while it pulls
+	waitMu   *sync.Mutex
+	watchMu  *sync.Mutex
+	watchers map[chan struct{}]struct{}
+	watching atomic.Int32
+	// pulled and leases are guarded by headMu
+	pulled int64
+	leases *leaseState[V]
+	// requeued is the number of redelivered elements kept by leases, Size counts them,
+	// leasing is set once leases are used, so TryPull can not skip the timed out ones
+	requeued atomic.Int64
+	leasing  atomic.Bool
 }
 
 func NewSyncRubberRing[V any](options ...applyConfigFunc) *SyncRubberRing[V] {
-	return &SyncRubberRing[V]{
-		ring: NewRubberRing[V](options...),
-		cond: syncutils.NewCond(),
-		mu:   &sync.Mutex{},
+	return newSyncRubberRing(NewRubberRing[V](options...))
+}
+
+func newSyncRubberRing[V any](ring *RubberRing[V]) *SyncRubberRing[V] {
+	r := &SyncRubberRing[V]{
+		ring:    ring,
+		cond:    syncutils.NewCond(),
+		headMu:  &sync.Mutex{},
+		tailMu:  &sync.Mutex{},
+		waitMu:  &sync.Mutex{},
+		watchMu: &sync.Mutex{},
 	}
+	r.size.Store(int64(ring.size))
+	r.capacity.Store(int64(ring.capacity))
+	return r
 }
 
 func (r *SyncRubberRing[V]) Size() int {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	return r.ring.Size()
+	return int(r.size.Load() + r.requeued.Load())
 }
 
 func (r *SyncRubberRing[V]) Capacity() int {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	return r.ring.Capacity()
+	return int(r.capacity.Load())
 }
 
 func (r *SyncRubberRing[V]) Stat() RubberRingStat {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	r.lockAll()
+	defer r.unlockAll()
 	return stat(r.ring)
 }
 
+// ToSlice returns the redelivered elements first and then the elements of the ring
+func (r *SyncRubberRing[V]) ToSlice() []V {
+	return r.AppendTo(make([]V, 0, r.Size()))
+}
+
+func (r *SyncRubberRing[V]) AppendTo(dst []V) []V {
+	r.lockAll()
+	defer r.unlockAll()
+	return r.ring.AppendTo(r.appendLeasedLocked(dst, false))
+}
+
+// Snapshot also includes the elements in flight before the others, so nothing is lost if it is restored
+func (r *SyncRubberRing[V]) Snapshot() RingSnapshot[V] {
+	r.lockAll()
+	defer r.unlockAll()
+	return RingSnapshot[V]{
+		elements: r.ring.AppendTo(r.appendLeasedLocked(nil, true)),
+		stat:     stat(r.ring),
+	}
+}
+
 func (r *SyncRubberRing[V]) Push(value V) {
-	r.mu.Lock()
-	r.ring.Push(value)
-	r.cond.Signal()
-	r.mu.Unlock()
+	r.tailMu.Lock()
+	r.capacity.Add(int64(r.ring.pushElement(value, r.ring.deadline(r.ring.config.ttl), r.Capacity())))
+	r.size.Add(1)
+	r.tailMu.Unlock()
+	r.signal()
 }
 
 func (r *SyncRubberRing[V]) Pull(ctx context.Context) (V, error) {
+	_, v, err := r.PullWithOffset(ctx)
+	return v, err
+}
+
+// PullWithOffset works like Pull and also returns the offset of the element
+func (r *SyncRubberRing[V]) PullWithOffset(ctx context.Context) (uint64, V, error) {
 	var v V
-	r.mu.Lock()
+	r.headMu.Lock()
 	for {
-		if r.ring.Size() > 0 {
-			v, err := r.ring.Pull()
-			r.mu.Unlock()
-			return v, err
+		if offset, v, ok := r.pullLocked(); ok {
+			r.headMu.Unlock()
+			return offset, v, nil
+		}
+		if r.leases != nil {
+			// the elements of timed out leases come back without a push, so wait like Lease
+			r.headMu.Unlock()
+			entry, err := waitLeased(ctx, r, func(time.Time) (leaseEntry[V], bool) {
+				return r.pullEntryLocked()
+			})
+			return entry.offset, entry.value, err
+		}
+		// a producer increments the size before it checks the waiters,
+		// so either the size is seen here or the producer signals under waitMu
+		// after the wait is registered
+		r.waitMu.Lock()
+		r.waiters.Add(1)
+		if r.size.Load() > 0 {
+			r.waiters.Add(-1)
+			r.waitMu.Unlock()
+			continue
 		}
 		wait := r.cond.Wait()
-		r.mu.Unlock()
+		r.waitMu.Unlock()
+		r.headMu.Unlock()
 		select {
 		case <-wait:
 		case <-ctx.Done():
-			return v, ctx.Err()
+			r.waiters.Add(-1)
+			select {
+			case <-wait:
+				// the signal was meant for an element, pass it to another waiter
+				r.signal()
+			default:
+			}
+			return 0, v, ctx.Err()
 		}
-		r.mu.Lock()
+		r.headMu.Lock()
+		r.waiters.Add(-1)
 	}
 }
 
+// TryPull returns io.EOF instead of waiting if the ring is empty
+func (r *SyncRubberRing[V]) TryPull() (V, error) {
+	var v V
+	if r.Size() == 0 && !r.leasing.Load() {
+		return v, io.EOF
+	}
+	r.headMu.Lock()
+	defer r.headMu.Unlock()
+	_, v, ok := r.pullLocked()
+	if !ok {
+		return v, io.EOF
+	}
+	return v, nil
+}
+
+func (r *SyncRubberRing[V]) OffsetRange() (first, last uint64) {
+	r.lockAll()
+	defer r.unlockAll()
+	return r.ring.OffsetRange()
+}
+
+// SeekTo moves the start of the ring to the offset, see RubberRing.SeekTo
+func (r *SyncRubberRing[V]) SeekTo(offset uint64) error {
+	r.lockAll()
+	err := r.ring.SeekTo(offset)
+	r.unlockAll()
+	if err == nil && r.Size() > 0 {
+		r.signal()
+	}
+	return err
+}
+
 func (r *SyncRubberRing[V]) Elements(ctx context.Context) iter.Seq[V] {
 	return func(yield func(V) bool) {
 		for {
@@ -80,3 +195,152 @@ func (r *SyncRubberRing[V]) Elements(ctx context.Context) iter.Seq[V] {
 		}
 	}
 }
+
+func (r *SyncRubberRing[V]) pullLocked() (uint64, V, bool) {
+	entry, ok := r.pullEntryLocked()
+	return entry.offset, entry.value, ok
+}
+
+// pullEntryLocked must be called with headMu held, it returns a due redelivered element first,
+// skips expired elements and returns false if no element is left
+func (r *SyncRubberRing[V]) pullEntryLocked() (leaseEntry[V], bool) {
+	if r.leases != nil {
+		if entry, ok := r.redeliverLocked(); ok {
+			return entry, true
+		}
+	}
+	var now int64
+	if r.ring.expiring {
+		now = r.ring.config.clock.Now().UnixNano()
+	}
+	for r.size.Load() > 0 {
+		offset := r.ring.headOffset
+		expired := r.ring.expiring && r.ring.headExpired(now)
+		v, released := r.ring.pullElement()
+		r.pulled++
+		r.size.Add(-1)
+		r.capacity.Add(-int64(released))
+		if expired {
+			r.ring.expire(v)
+			continue
+		}
+		return leaseEntry[V]{value: v, offset: offset}, true
+	}
+	return leaseEntry[V]{}, false
+}
+
+// PushWithTTL puts the element that Pull skips after ttl
+func (r *SyncRubberRing[V]) PushWithTTL(value V, ttl time.Duration) {
+	r.tailMu.Lock()
+	if !r.ring.expiring {
+		// the flag and the deadlines of the current chunk are read by both ends of the ring
+		r.tailMu.Unlock()
+		r.lockAll()
+		if !r.ring.expiring {
+			r.ring.expiring = true
+			if r.ring.endChank.deadlines == nil {
+				r.ring.endChank.deadlines = make([]int64, len(r.ring.endChank.data))
+			}
+		}
+		r.unlockAll()
+		r.tailMu.Lock()
+	}
+	r.capacity.Add(int64(r.ring.pushElement(value, r.ring.deadline(ttl), r.Capacity())))
+	r.size.Add(1)
+	r.tailMu.Unlock()
+	r.signal()
+}
+
+// OnExpired sets the callback that receives the elements skipped because of TTL,
+// it is called while the ring is locked, so it must not use the ring
+func (r *SyncRubberRing[V]) OnExpired(fn func(V)) {
+	r.headMu.Lock()
+	defer r.headMu.Unlock()
+	r.ring.onExpired = fn
+}
+
+// Expired returns the number of elements skipped because of TTL
+func (r *SyncRubberRing[V]) Expired() uint64 {
+	r.headMu.Lock()
+	defer r.headMu.Unlock()
+	return r.ring.expired
+}
+
+// Sweep drops the expired elements from the start of the ring, returns their number
+func (r *SyncRubberRing[V]) Sweep() int {
+	r.headMu.Lock()
+	defer r.headMu.Unlock()
+	if !r.ring.expiring {
+		return 0
+	}
+	now := r.ring.config.clock.Now().UnixNano()
+	swept := 0
+	for r.size.Load() > 0 && r.ring.headExpired(now) {
+		v, released := r.ring.pullElement()
+		r.pulled++
+		r.size.Add(-1)
+		r.capacity.Add(-int64(released))
+		r.ring.expire(v)
+		swept++
+	}
+	return swept
+}
+
+func (r *SyncRubberRing[V]) signal() {
+	if r.watching.Load() > 0 {
+		r.notifyWatchers()
+	}
+	if r.waiters.Load() == 0 {
+		return
+	}
+	r.waitMu.Lock()
+	r.cond.Signal()
+	r.waitMu.Unlock()
+}
+
+// watch registers a channel that gets a non-blocking send on every new element,
+// it is used to wait on several rings at once
+func (r *SyncRubberRing[V]) watch(ch chan struct{}) {
+	r.watchMu.Lock()
+	defer r.watchMu.Unlock()
+	if r.watchers == nil {
+		r.watchers = make(map[chan struct{}]struct{})
+	}
+	r.watchers[ch] = struct{}{}
+	r.watching.Add(1)
+}
+
+func (r *SyncRubberRing[V]) unwatch(ch chan struct{}) {
+	r.watchMu.Lock()
+	defer r.watchMu.Unlock()
+	delete(r.watchers, ch)
+	r.watching.Add(-1)
+}
+
+func (r *SyncRubberRing[V]) notifyWatchers() {
+	r.watchMu.Lock()
+	defer r.watchMu.Unlock()
+	for ch := range r.watchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// lockAll stops both ends of the ring and brings the counters of the inner ring up to date,
+// so methods of RubberRing can be used on it
+func (r *SyncRubberRing[V]) lockAll() {
+	r.headMu.Lock()
+	r.tailMu.Lock()
+	r.ring.size = int(r.size.Load())
+	r.ring.capacity = r.Capacity()
+}
+
+// unlockAll publishes the counters of the inner ring changed under lockAll
+func (r *SyncRubberRing[V]) unlockAll() {
+	r.size.Store(int64(r.ring.size))
+	r.capacity.Store(int64(r.ring.capacity))
+	r.tailMu.Unlock()
+	r.headMu.Unlock()
+}
@@ -2,9 +2,13 @@ package rubberring
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"sync"
 	"testing"
 	"time"
 
+	syncutils "github.com/Skrip42/syncUtils"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/goleak"
 )
@@ -57,6 +61,64 @@ func (s *SyncRubberRingSuite) TestBasicOperations() {
 	s.Equal(6, s.ring.Capacity())
 }
 
+func (s *SyncRubberRingSuite) TestTryPull() {
+	_, err := s.ring.TryPull()
+	s.Equal(io.EOF, err)
+
+	s.ring.Push(1)
+	val, err := s.ring.TryPull()
+	s.NoError(err)
+	s.Equal(1, val)
+	s.Equal(0, s.ring.Size())
+}
+
+func (s *SyncRubberRingSuite) TestReplay() {
+	ring := NewSyncRubberRing[int](WithRetention(10))
+	for i := range 5 {
+		ring.Push(i)
+	}
+	for i := range 5 {
+		offset, val, err := ring.PullWithOffset(context.Background())
+		s.NoError(err)
+		s.Equal(uint64(i), offset)
+		s.Equal(i, val)
+	}
+	first, last := ring.OffsetRange()
+	s.Equal(uint64(0), first)
+	s.Equal(uint64(5), last)
+
+	s.NoError(ring.SeekTo(2))
+	s.Equal(3, ring.Size())
+	val, err := ring.Pull(context.Background())
+	s.NoError(err)
+	s.Equal(2, val)
+}
+
+func (s *SyncRubberRingSuite) TestElementTTL() {
+	clock := newFakeClock()
+	ring := NewSyncRubberRing[int](WithClock(clock))
+	expired := []int{}
+	ring.OnExpired(func(v int) { expired = append(expired, v) })
+
+	ring.PushWithTTL(1, time.Second)
+	ring.Push(2)
+	ring.PushWithTTL(3, time.Second)
+	clock.Advance(time.Second)
+
+	val, err := ring.Pull(context.Background())
+	s.NoError(err)
+	s.Equal(2, val)
+	_, err = ring.TryPull()
+	s.Equal(io.EOF, err)
+	s.Equal([]int{1, 3}, expired)
+	s.Equal(uint64(2), ring.Expired())
+
+	ring.PushWithTTL(4, time.Second)
+	clock.Advance(time.Second)
+	s.Equal(1, ring.Sweep())
+	s.Equal(0, ring.Size())
+}
+
 func (s *SyncRubberRingSuite) TestContextCancellation() {
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -132,6 +194,23 @@ func (s *SyncRubberRingSuite) TestStat() {
 	s.Equal([]int{3, 3}, stat.ActiveChanksSize)
 }
 
+func (s *SyncRubberRingSuite) TestSnapshot() {
+	for i := range 5 {
+		s.ring.Push(i)
+	}
+
+	snapshot := s.ring.Snapshot()
+	s.ring.Push(5)
+	_, err := s.ring.Pull(context.Background())
+	s.NoError(err)
+
+	s.Equal(5, snapshot.Size())
+	s.Equal([]int{0, 1, 2, 3, 4}, snapshot.ToSlice())
+	s.Equal(5, snapshot.Stat().Size)
+	s.Equal([]int{1, 2, 3, 4, 5}, s.ring.ToSlice())
+	s.Equal([]int{0, 1, 2, 3, 4, 5}, s.ring.AppendTo([]int{0}))
+}
+
 func (s *SyncRubberRingSuite) TestElements() {
 	ctx, canceled := context.WithCancel(context.Background())
 
@@ -184,3 +263,98 @@ func (s *SyncRubberRingSuite) TestElementsWithContextCancellation() {
 	// Verify that not all values were consumed
 	s.Greater(s.ring.Size(), 0)
 }
+
+func (s *SyncRubberRingSuite) TestProducerConsumerInParallel() {
+	const count = 50000
+	ctx := context.Background()
+	go func() {
+		for i := range count {
+			s.ring.Push(i)
+		}
+	}()
+
+	for i := range count {
+		v, err := s.ring.Pull(ctx)
+		s.Require().NoError(err)
+		s.Require().Equal(i, v)
+	}
+	s.Equal(0, s.ring.Size())
+	s.Equal(s.ring.Stat().Capacity, s.ring.Capacity())
+}
+
+// singleLockRing is the previous design of SyncRubberRing, kept to compare contention
+type singleLockRing[V any] struct {
+	ring *RubberRing[V]
+	cond *syncutils.Cond
+	mu   *sync.Mutex
+}
+
+func newSingleLockRing[V any]() *singleLockRing[V] {
+	return &singleLockRing[V]{
+		ring: NewRubberRing[V](),
+		cond: syncutils.NewCond(),
+		mu:   &sync.Mutex{},
+	}
+}
+
+func (r *singleLockRing[V]) Push(value V) {
+	r.mu.Lock()
+	r.ring.Push(value)
+	r.cond.Signal()
+	r.mu.Unlock()
+}
+
+func (r *singleLockRing[V]) Pull(ctx context.Context) (V, error) {
+	var v V
+	r.mu.Lock()
+	for {
+		if r.ring.Size() > 0 {
+			v, err := r.ring.Pull()
+			r.mu.Unlock()
+			return v, err
+		}
+		wait := r.cond.Wait()
+		r.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return v, ctx.Err()
+		}
+		r.mu.Lock()
+	}
+}
+
+func benchmarkContention(b *testing.B, pairs int, push func(int), pull func()) {
+	wg := &sync.WaitGroup{}
+	for p := range pairs {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := p; i < b.N; i += pairs {
+				push(i)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := p; i < b.N; i += pairs {
+				pull()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkSyncRubberRingContention(b *testing.B) {
+	for _, pairs := range []int{1, 4} {
+		b.Run(fmt.Sprintf("two-lock/pairs=%d", pairs), func(b *testing.B) {
+			ring := NewSyncRubberRing[int]()
+			ctx := context.Background()
+			benchmarkContention(b, pairs, ring.Push, func() { ring.Pull(ctx) })
+		})
+		b.Run(fmt.Sprintf("single-lock/pairs=%d", pairs), func(b *testing.B) {
+			ring := newSingleLockRing[int]()
+			ctx := context.Background()
+			benchmarkContention(b, pairs, ring.Push, func() { ring.Pull(ctx) })
+		})
+	}
+}
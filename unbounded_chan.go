@@ -0,0 +1,36 @@
+package rubberring
+
+// NewUnboundedChan returns a channel pair with unlimited buffering between them,
+// out is closed after in is closed and all buffered elements are received
+func NewUnboundedChan[V any](options ...applyConfigFunc) (chan<- V, <-chan V) {
+	in := make(chan V)
+	return in, Bridge(NewRubberRing[V](options...), in)
+}
+
+// Bridge starts a goroutine that moves elements from in through the ring to the returned channel,
+// the ring must not be used by anyone else until the returned channel is closed.
+// The goroutine exits when in is closed and the ring is drained, so the returned channel must be read to the end.
+func Bridge[V any](ring *RubberRing[V], in <-chan V) <-chan V {
+	out := make(chan V)
+	go func() {
+		defer close(out)
+		for in != nil || ring.Size() > 0 {
+			var send chan<- V
+			next, err := ring.Peek()
+			if err == nil {
+				send = out
+			}
+			select {
+			case v, ok := <-in:
+				if !ok {
+					in = nil
+					continue
+				}
+				ring.Push(v)
+			case send <- next:
+				ring.discard()
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,87 @@
+package rubberring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/goleak"
+)
+
+type UnboundedChanSuite struct {
+	suite.Suite
+}
+
+func (s *UnboundedChanSuite) TearDownTest() {
+	s.NoError(goleak.Find())
+}
+
+func (s *UnboundedChanSuite) TestBuffersWithoutReader() {
+	in, out := NewUnboundedChan[int](WithStartChankSize(4), WithStartChankCount(1))
+	for i := range 100 {
+		in <- i
+	}
+	close(in)
+
+	result := make([]int, 0, 100)
+	for v := range out {
+		result = append(result, v)
+	}
+	s.Len(result, 100)
+	for i, v := range result {
+		s.Equal(i, v)
+	}
+}
+
+func (s *UnboundedChanSuite) TestConcurrent() {
+	in, out := NewUnboundedChan[int]()
+	go func() {
+		for i := range 10000 {
+			in <- i
+		}
+		close(in)
+	}()
+
+	expected := 0
+	for v := range out {
+		s.Equal(expected, v)
+		expected++
+	}
+	s.Equal(10000, expected)
+}
+
+func (s *UnboundedChanSuite) TestBridgeDrainsRing() {
+	ring := NewRubberRing[int]()
+	ring.Push(1)
+	ring.Push(2)
+	in := make(chan int)
+	out := Bridge(ring, in)
+	in <- 3
+	close(in)
+
+	result := []int{}
+	for v := range out {
+		result = append(result, v)
+	}
+	s.Equal([]int{1, 2, 3}, result)
+	s.Equal(0, ring.Size())
+}
+
+func (s *UnboundedChanSuite) TestElementTTL() {
+	clock := newFakeClock()
+	in, out := NewUnboundedChan[int](WithElementTTL(time.Second), WithClock(clock))
+	in <- 1
+	clock.Advance(time.Second)
+	in <- 2
+	close(in)
+
+	result := []int{}
+	for v := range out {
+		result = append(result, v)
+	}
+	s.Equal([]int{2}, result)
+}
+
+func TestUnboundedChanSuite(t *testing.T) {
+	suite.Run(t, new(UnboundedChanSuite))
+}
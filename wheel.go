@@ -0,0 +1,258 @@
+package rubberring
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrWheelRunning = errors.New("rubberring: wheel is already running")
+
+type wheelConfig struct {
+	tick        time.Duration
+	size        int
+	levels      int
+	poolSize    int
+	slotOptions []applyConfigFunc
+}
+
+type applyWheelConfigFunc func(c *wheelConfig)
+
+// WithTick sets the resolution of the wheel (10ms by default)
+func WithTick(tick time.Duration) applyWheelConfigFunc {
+	if tick <= 0 {
+		tick = 10 * time.Millisecond
+	}
+	return func(c *wheelConfig) {
+		c.tick = tick
+	}
+}
+
+// WithWheelSize sets the number of slots of every level (256 by default)
+func WithWheelSize(size int) applyWheelConfigFunc {
+	if size < 2 {
+		size = 2
+	}
+	return func(c *wheelConfig) {
+		c.size = size
+	}
+}
+
+// WithLevels sets the number of levels (4 by default), timers beyond the last level
+// are moved to it again until they fit
+func WithLevels(levels int) applyWheelConfigFunc {
+	if levels < 1 {
+		levels = 1
+	}
+	return func(c *wheelConfig) {
+		c.levels = levels
+	}
+}
+
+// WithSlotOptions passes options to the RubberRing of every slot,
+// drained chunks of all slots go to a shared pool of WithPassiveChankBufferSize chunks.
+// WithElementTTL is ignored, timers are not dropped. The clock of WithClock drives Run.
+func WithSlotOptions(options ...applyConfigFunc) applyWheelConfigFunc {
+	return func(c *wheelConfig) {
+		c.slotOptions = append(c.slotOptions, options...)
+	}
+}
+
+type Timer struct {
+	deadline uint64
+	fn       func()
+	canceled bool
+}
+
+// Wheel is a hierarchical timing wheel. Every slot is a RubberRing of timers, so slots grow with the load
+// and give their chunks back to a pool shared by all slots when drained.
+type Wheel struct {
+	mu     *sync.Mutex
+	tick   time.Duration
+	size   uint64
+	clock  Clock
+	levels [][]*RubberRing[*Timer]
+	// spans[l] is the number of ticks covered by one slot of the level l
+	spans   []uint64
+	now     uint64
+	pending int
+	running atomic.Bool
+}
+
+func NewWheel(options ...applyWheelConfigFunc) *Wheel {
+	wc := wheelConfig{
+		tick:   10 * time.Millisecond,
+		size:   256,
+		levels: 4,
+	}
+	for _, option := range options {
+		option(&wc)
+	}
+	slotConfig := defaultConfig
+	slotConfig.startChankSize = 16
+	slotConfig.startChankCount = 1
+	slotConfig.pasiveChankBufferSize = 256
+	slotConfig.growStrategy = nil
+	for _, option := range wc.slotOptions {
+		option(&slotConfig)
+	}
+	slotConfig.ttl = 0
+	if slotConfig.growStrategy == nil {
+		chankSize := slotConfig.startChankSize
+		slotConfig.growStrategy = func(int) (int, int) { return chankSize, 1 }
+	}
+	pool := make(chan *chank[*Timer], slotConfig.pasiveChankBufferSize)
+
+	w := &Wheel{
+		mu:     &sync.Mutex{},
+		tick:   wc.tick,
+		size:   uint64(wc.size),
+		clock:  slotConfig.clock,
+		levels: make([][]*RubberRing[*Timer], wc.levels),
+		spans:  make([]uint64, wc.levels),
+	}
+	span := uint64(1)
+	for l := range w.levels {
+		w.spans[l] = span
+		span *= w.size
+		w.levels[l] = make([]*RubberRing[*Timer], wc.size)
+		for i := range w.levels[l] {
+			w.levels[l][i] = newPooledRubberRing(slotConfig, pool)
+		}
+	}
+	return w
+}
+
+// Size returns the number of scheduled timers
+func (w *Wheel) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.pending
+}
+
+// Capacity returns the capacity of all slots
+func (w *Wheel) Capacity() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	capacity := 0
+	for _, level := range w.levels {
+		for _, slot := range level {
+			capacity += slot.Capacity()
+		}
+	}
+	return capacity
+}
+
+// Schedule calls fn after d, rounded up to the tick. fn is called from the goroutine of Run,
+// so it must not block for long.
+func (w *Wheel) Schedule(d time.Duration, fn func()) *Timer {
+	ticks := uint64(1)
+	if d > w.tick {
+		ticks = uint64((d + w.tick - 1) / w.tick)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	timer := &Timer{deadline: w.now + ticks, fn: fn}
+	w.insertLocked(timer)
+	w.pending++
+	return timer
+}
+
+// Cancel stops the timer, it returns false if the timer has already fired or was canceled
+func (w *Wheel) Cancel(timer *Timer) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if timer.canceled || timer.fn == nil {
+		return false
+	}
+	timer.canceled = true
+	timer.fn = nil
+	w.pending--
+	return true
+}
+
+// Run drives the wheel by the clock until ctx is done,
+// it returns ErrWheelRunning if another Run has not returned yet
+func (w *Wheel) Run(ctx context.Context) error {
+	if !w.running.CompareAndSwap(false, true) {
+		return ErrWheelRunning
+	}
+	defer w.running.Store(false)
+	start := w.clock.Now()
+	w.mu.Lock()
+	base := w.now
+	w.mu.Unlock()
+	for {
+		elapsed := uint64(w.clock.Now().Sub(start) / w.tick)
+		w.mu.Lock()
+		var behind uint64
+		if target := base + elapsed; target > w.now {
+			behind = target - w.now
+		}
+		w.mu.Unlock()
+		for range behind {
+			w.advance()
+		}
+		next := start.Add(time.Duration(elapsed+1) * w.tick)
+		select {
+		case <-w.clock.After(next.Sub(w.clock.Now())):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// advance moves the wheel one tick forward and calls the timers that are due
+func (w *Wheel) advance() {
+	w.mu.Lock()
+	w.now++
+	// higher levels go first, their timers may move to the slots of lower levels cascaded right after
+	for l := len(w.levels) - 1; l > 0; l-- {
+		if w.now%w.spans[l] != 0 {
+			continue
+		}
+		slot := w.levels[l][(w.now/w.spans[l])%w.size]
+		for range slot.Size() {
+			timer, _ := slot.Pull()
+			if !timer.canceled {
+				w.insertLocked(timer)
+			}
+		}
+	}
+
+	slot := w.levels[0][w.now%w.size]
+	due := make([]func(), 0, slot.Size())
+	for range slot.Size() {
+		timer, _ := slot.Pull()
+		if timer.canceled {
+			continue
+		}
+		if timer.deadline > w.now {
+			w.insertLocked(timer)
+			continue
+		}
+		due = append(due, timer.fn)
+		timer.fn = nil
+		w.pending--
+	}
+	w.mu.Unlock()
+
+	for _, fn := range due {
+		fn()
+	}
+}
+
+// insertLocked puts the timer to the lowest level whose current round includes the deadline,
+// a timer cascaded at its deadline goes to the current slot of the first level, which fires right after
+func (w *Wheel) insertLocked(timer *Timer) {
+	deadline := max(timer.deadline, w.now)
+	top := len(w.levels) - 1
+	for l := range w.levels {
+		if l == top || deadline/(w.spans[l]*w.size) == w.now/(w.spans[l]*w.size) {
+			w.levels[l][(deadline/w.spans[l])%w.size].Push(timer)
+			return
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package rubberring
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/goleak"
+)
+
+type WheelSuite struct {
+	suite.Suite
+	wheel *Wheel
+	fired []int
+}
+
+func (s *WheelSuite) SetupTest() {
+	s.wheel = NewWheel(WithTick(time.Millisecond), WithWheelSize(4), WithLevels(3))
+	s.fired = nil
+}
+
+func (s *WheelSuite) TearDownTest() {
+	s.NoError(goleak.Find())
+	s.wheel = nil
+}
+
+func (s *WheelSuite) schedule(ticks int) *Timer {
+	return s.wheel.Schedule(time.Duration(ticks)*time.Millisecond, func() {
+		s.fired = append(s.fired, ticks)
+	})
+}
+
+func (s *WheelSuite) advanceTo(tick uint64) {
+	for s.wheel.now < tick {
+		s.wheel.advance()
+		for _, ticks := range s.fired {
+			s.Equal(uint64(ticks), s.wheel.now, "timer of %d ticks", ticks)
+		}
+		s.fired = s.fired[:0]
+	}
+}
+
+func (s *WheelSuite) TestFiresAtDeadline() {
+	// inside the first level, on the borders of levels and beyond the last level (64 ticks)
+	for _, ticks := range []int{1, 3, 4, 5, 15, 16, 17, 63, 64, 65, 100, 200} {
+		s.schedule(ticks)
+	}
+	s.Equal(12, s.wheel.Size())
+	s.advanceTo(300)
+	s.Equal(0, s.wheel.Size())
+}
+
+func (s *WheelSuite) TestScheduleLater() {
+	s.advanceTo(7)
+	for _, ticks := range []int{1, 2, 9, 30, 57} {
+		deadline := s.wheel.now + uint64(ticks)
+		s.wheel.Schedule(time.Duration(ticks)*time.Millisecond, func() {
+			s.Equal(deadline, s.wheel.now)
+		})
+	}
+	for range 100 {
+		s.wheel.advance()
+	}
+	s.Equal(0, s.wheel.Size())
+}
+
+func (s *WheelSuite) TestRoundsUpToTick() {
+	called := false
+	s.wheel.Schedule(1500*time.Microsecond, func() { called = true })
+	s.wheel.advance()
+	s.False(called)
+	s.wheel.advance()
+	s.True(called)
+}
+
+func (s *WheelSuite) TestCancel() {
+	timer := s.schedule(10)
+	other := s.schedule(20)
+	s.True(s.wheel.Cancel(timer))
+	s.False(s.wheel.Cancel(timer))
+	s.Equal(1, s.wheel.Size())
+
+	s.advanceTo(30)
+	s.Empty(s.fired)
+	s.False(s.wheel.Cancel(other))
+}
+
+func (s *WheelSuite) TestSlotsShrink() {
+	s.wheel = NewWheel(WithTick(time.Millisecond), WithWheelSize(4), WithLevels(2),
+		WithSlotOptions(WithStartChankSize(4), WithPassiveChankBufferSize(2)))
+	initial := s.wheel.Capacity()
+	for range 100 {
+		s.wheel.Schedule(time.Millisecond, func() {})
+	}
+	s.Greater(s.wheel.Capacity(), initial+90)
+
+	s.wheel.advance()
+	s.Equal(0, s.wheel.Size())
+	s.Equal(initial, s.wheel.Capacity())
+}
+
+func (s *WheelSuite) TestRun() {
+	clock := newFakeClock()
+	s.wheel = NewWheel(WithTick(time.Millisecond), WithSlotOptions(WithClock(clock)))
+
+	mu := &sync.Mutex{}
+	fired := 0
+	for i := 1; i <= 5; i++ {
+		s.wheel.Schedule(time.Duration(i)*time.Millisecond, func() {
+			mu.Lock()
+			fired++
+			mu.Unlock()
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.ErrorIs(s.wheel.Run(ctx), context.Canceled)
+	}()
+	s.Eventually(func() bool { return clock.Timers() > 0 }, time.Second, time.Millisecond)
+	clock.Advance(3 * time.Millisecond)
+	s.Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return fired == 3
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+	s.Equal(2, s.wheel.Size())
+}
+
+func (s *WheelSuite) TestElementTTLIgnored() {
+	clock := newFakeClock()
+	s.wheel = NewWheel(WithTick(time.Millisecond), WithWheelSize(4), WithLevels(2),
+		WithSlotOptions(WithElementTTL(time.Millisecond), WithClock(clock)))
+	called := false
+	s.wheel.Schedule(10*time.Millisecond, func() { called = true })
+	clock.Advance(time.Hour)
+	s.advanceTo(20)
+	s.True(called)
+}
+
+func (s *WheelSuite) TestConcurrentRun() {
+	clock := newFakeClock()
+	s.wheel = NewWheel(WithTick(time.Millisecond), WithSlotOptions(WithClock(clock)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.ErrorIs(s.wheel.Run(ctx), context.Canceled)
+	}()
+	s.Eventually(func() bool { return clock.Timers() > 0 }, time.Second, time.Millisecond)
+	s.ErrorIs(s.wheel.Run(context.Background()), ErrWheelRunning)
+
+	cancel()
+	<-done
+	// the wheel can be run again once the previous Run has returned
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	s.ErrorIs(s.wheel.Run(ctx), context.Canceled)
+}
+
+func TestWheelSuite(t *testing.T) {
+	suite.Run(t, new(WheelSuite))
+}
@@ -0,0 +1,209 @@
+package rubberring
+
+import (
+	"sync"
+	"time"
+)
+
+// Aggregator is a monoid over the elements: Combine must be associative and Identity must not change
+// the other argument. Aggregates are combined in the order of elements.
+type Aggregator[V, A any] interface {
+	Identity() A
+	Lift(v V) A
+	Combine(a, b A) A
+}
+
+type Real interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+type windowItem[V any] struct {
+	at    time.Time
+	value V
+}
+
+// WindowRing keeps the elements pushed within the last duration and maintains their aggregate.
+// It uses two stacks: the newest elements are folded into one aggregate, and the oldest have suffix
+// aggregates rebuilt only when all of them are evicted, so every operation is amortized O(1).
+// Timestamps of the pushed elements must not decrease.
+type WindowRing[V, A any] struct {
+	mu       *sync.Mutex
+	duration time.Duration
+	agg      Aggregator[V, A]
+	clock    Clock
+	ring     *RubberRing[windowItem[V]]
+	// front holds the suffix aggregates of the oldest elements starting from frontPos
+	front    []A
+	frontPos int
+	back     A
+}
+
+// NewWindowRing accepts the options of RubberRing, WithElementTTL is ignored, the window drops the elements itself
+func NewWindowRing[V, A any](
+	duration time.Duration,
+	agg Aggregator[V, A],
+	options ...applyConfigFunc,
+) *WindowRing[V, A] {
+	ring := NewRubberRing[windowItem[V]](append(options[:len(options):len(options)], withoutTTL())...)
+	return &WindowRing[V, A]{
+		mu:       &sync.Mutex{},
+		duration: duration,
+		agg:      agg,
+		clock:    ring.config.clock,
+		ring:     ring,
+		back:     agg.Identity(),
+	}
+}
+
+func (r *WindowRing[V, A]) Size() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.evictLocked(r.clock.Now())
+	return r.ring.Size()
+}
+
+func (r *WindowRing[V, A]) Push(value V) {
+	r.PushAt(r.clock.Now(), value)
+}
+
+func (r *WindowRing[V, A]) PushAt(at time.Time, value V) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.evictLocked(at)
+	r.ring.Push(windowItem[V]{at: at, value: value})
+	r.back = r.agg.Combine(r.back, r.agg.Lift(value))
+}
+
+// Aggregate returns the aggregate of the elements within the window
+func (r *WindowRing[V, A]) Aggregate() A {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.evictLocked(r.clock.Now())
+	if r.frontPos < len(r.front) {
+		return r.agg.Combine(r.front[r.frontPos], r.back)
+	}
+	return r.back
+}
+
+// ToSlice returns the elements within the window
+func (r *WindowRing[V, A]) ToSlice() []V {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.evictLocked(r.clock.Now())
+	values := make([]V, 0, r.ring.Size())
+	for item := range r.ring.all() {
+		values = append(values, item.value)
+	}
+	return values
+}
+
+func (r *WindowRing[V, A]) evictLocked(now time.Time) {
+	border := now.Add(-r.duration)
+	for {
+		item, err := r.ring.Peek()
+		if err != nil || item.at.After(border) {
+			return
+		}
+		if r.frontPos == len(r.front) {
+			r.rebuildFrontLocked()
+		}
+		_, _ = r.ring.Pull()
+		r.frontPos++
+	}
+}
+
+// rebuildFrontLocked moves all elements to the front stack, it is called when the front stack is empty
+func (r *WindowRing[V, A]) rebuildFrontLocked() {
+	clear(r.front)
+	r.front = r.front[:0]
+	for item := range r.ring.all() {
+		r.front = append(r.front, r.agg.Lift(item.value))
+	}
+	for i := len(r.front) - 2; i >= 0; i-- {
+		r.front[i] = r.agg.Combine(r.front[i], r.front[i+1])
+	}
+	r.frontPos = 0
+	r.back = r.agg.Identity()
+}
+
+type countAggregator[V any] struct{}
+
+// Count counts the elements
+func Count[V any]() Aggregator[V, int] {
+	return countAggregator[V]{}
+}
+
+func (countAggregator[V]) Identity() int        { return 0 }
+func (countAggregator[V]) Lift(V) int           { return 1 }
+func (countAggregator[V]) Combine(a, b int) int { return a + b }
+
+type sumAggregator[V Real] struct{}
+
+// Sum sums the elements
+func Sum[V Real]() Aggregator[V, V] {
+	return sumAggregator[V]{}
+}
+
+func (sumAggregator[V]) Identity() V      { return 0 }
+func (sumAggregator[V]) Lift(v V) V       { return v }
+func (sumAggregator[V]) Combine(a, b V) V { return a + b }
+
+// Extremum is the result of Min and Max, Valid is false for an empty window
+type Extremum[V Real] struct {
+	Value V
+	Valid bool
+}
+
+type extremumAggregator[V Real] struct {
+	less bool
+}
+
+// Min finds the least element
+func Min[V Real]() Aggregator[V, Extremum[V]] {
+	return extremumAggregator[V]{less: true}
+}
+
+// Max finds the greatest element
+func Max[V Real]() Aggregator[V, Extremum[V]] {
+	return extremumAggregator[V]{}
+}
+
+func (extremumAggregator[V]) Identity() Extremum[V] { return Extremum[V]{} }
+func (extremumAggregator[V]) Lift(v V) Extremum[V]  { return Extremum[V]{Value: v, Valid: true} }
+func (e extremumAggregator[V]) Combine(a, b Extremum[V]) Extremum[V] {
+	if !a.Valid {
+		return b
+	}
+	if !b.Valid || (a.Value < b.Value) == e.less || a.Value == b.Value {
+		return a
+	}
+	return b
+}
+
+// Mean is the result of Average
+type Mean struct {
+	Sum   float64
+	Count int
+}
+
+func (m Mean) Value() float64 {
+	if m.Count == 0 {
+		return 0
+	}
+	return m.Sum / float64(m.Count)
+}
+
+type meanAggregator[V Real] struct{}
+
+// Average computes the mean of the elements
+func Average[V Real]() Aggregator[V, Mean] {
+	return meanAggregator[V]{}
+}
+
+func (meanAggregator[V]) Identity() Mean { return Mean{} }
+func (meanAggregator[V]) Lift(v V) Mean  { return Mean{Sum: float64(v), Count: 1} }
+func (meanAggregator[V]) Combine(a, b Mean) Mean {
+	return Mean{Sum: a.Sum + b.Sum, Count: a.Count + b.Count}
+}
@@ -0,0 +1,98 @@
+package rubberring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WindowRingSuite struct {
+	suite.Suite
+	clock *fakeClock
+}
+
+func (s *WindowRingSuite) SetupTest() {
+	s.clock = newFakeClock()
+}
+
+func (s *WindowRingSuite) TestCount() {
+	ring := NewWindowRing(time.Minute, Count[int](), WithClock(s.clock))
+	for range 10 {
+		ring.Push(1)
+		s.clock.Advance(10 * time.Second)
+	}
+	// the elements pushed 60 seconds ago and earlier are out of the window
+	s.Equal(5, ring.Aggregate())
+	s.Equal(5, ring.Size())
+}
+
+func (s *WindowRingSuite) TestSumMinMaxAverage() {
+	values := []int{5, 1, 9, 3, 7, 2, 8}
+	sum := NewWindowRing(3*time.Second, Sum[int](), WithClock(s.clock), WithStartChankSize(2))
+	least := NewWindowRing(3*time.Second, Min[int](), WithClock(s.clock))
+	greatest := NewWindowRing(3*time.Second, Max[int](), WithClock(s.clock))
+	mean := NewWindowRing(3*time.Second, Average[int](), WithClock(s.clock))
+
+	s.False(least.Aggregate().Valid)
+	for i, v := range values {
+		sum.Push(v)
+		least.Push(v)
+		greatest.Push(v)
+		mean.Push(v)
+
+		window := values[max(0, i-2) : i+1]
+		expectedSum, expectedMin, expectedMax := 0, window[0], window[0]
+		for _, w := range window {
+			expectedSum += w
+			expectedMin = min(expectedMin, w)
+			expectedMax = max(expectedMax, w)
+		}
+		s.Equal(expectedSum, sum.Aggregate())
+		s.Equal(Extremum[int]{Value: expectedMin, Valid: true}, least.Aggregate())
+		s.Equal(Extremum[int]{Value: expectedMax, Valid: true}, greatest.Aggregate())
+		s.InDelta(float64(expectedSum)/float64(len(window)), mean.Aggregate().Value(), 1e-9)
+		s.Equal(window, sum.ToSlice())
+
+		s.clock.Advance(time.Second)
+	}
+
+	s.clock.Advance(time.Hour)
+	s.Equal(0, sum.Aggregate())
+	s.False(greatest.Aggregate().Valid)
+	s.Equal(0.0, mean.Aggregate().Value())
+}
+
+type concatAggregator struct{}
+
+func (concatAggregator) Identity() string           { return "" }
+func (concatAggregator) Lift(v string) string       { return v }
+func (concatAggregator) Combine(a, b string) string { return a + b }
+
+func (s *WindowRingSuite) TestOrderOfCombine() {
+	ring := NewWindowRing[string, string](2*time.Second, concatAggregator{}, WithClock(s.clock))
+	for _, v := range []string{"a", "b", "c", "d", "e"} {
+		ring.Push(v)
+		s.clock.Advance(time.Second)
+	}
+	s.Equal("e", ring.Aggregate())
+
+	now := s.clock.Now()
+	ring.PushAt(now, "f")
+	ring.PushAt(now, "g")
+	s.Equal("efg", ring.Aggregate())
+}
+
+func (s *WindowRingSuite) TestElementTTLIgnored() {
+	ring := NewWindowRing(time.Minute, Count[int](), WithClock(s.clock), WithElementTTL(time.Second))
+	for range 3 {
+		ring.Push(1)
+		s.clock.Advance(10 * time.Second)
+	}
+	s.Equal(3, ring.Aggregate())
+	s.Equal(3, ring.Size())
+}
+
+func TestWindowRingSuite(t *testing.T) {
+	suite.Run(t, new(WindowRingSuite))
+}